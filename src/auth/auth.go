@@ -0,0 +1,176 @@
+// Package auth verifies the credentials declared in api.yaml: a static API key
+// in the X-API-Key header (ApiKeyAuth) or an HMAC-signed JWT in the
+// Authorization header (BearerAuth).
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"store_product/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// APIKeyHeader is the header carrying an API key
+const APIKeyHeader = "X-API-Key"
+
+// clockSkew is the leeway allowed when checking token expiry and not-before times
+const clockSkew = 30 * time.Second
+
+// Authentication errors
+var (
+	ErrMissingCredentials = errors.New("missing credentials")
+	ErrInvalidAPIKey      = errors.New("invalid API key")
+	ErrInvalidToken       = errors.New("invalid bearer token")
+)
+
+// Scheme names the way a caller authenticated
+type Scheme string
+
+// Supported authentication schemes
+const (
+	SchemeAPIKey Scheme = "api_key"
+	SchemeBearer Scheme = "bearer"
+)
+
+// RoleAdmin lets a principal act on any customer's carts
+const RoleAdmin = "admin"
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Subject    string // API client name or the token's "sub" claim
+	Scheme     Scheme
+	CustomerID int // customer the caller acts for; 0 when it is not a customer
+	Roles      []string
+}
+
+// IsAdmin reports whether the principal holds the admin role
+func (p *Principal) IsAdmin() bool {
+	return p != nil && slices.Contains(p.Roles, RoleAdmin)
+}
+
+// CanActFor reports whether the principal may read or change data belonging
+// to the given customer: admins may act for anyone, customers only for themselves
+func (p *Principal) CanActFor(customerID int) bool {
+	if p == nil {
+		return false
+	}
+	return p.IsAdmin() || (p.CustomerID != 0 && p.CustomerID == customerID)
+}
+
+// tokenClaims are the claims read from a bearer token
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	CustomerID int      `json:"customer_id,omitempty"`
+	Roles      []string `json:"roles,omitempty"`
+}
+
+// Authenticator checks request credentials against the configured keys
+type Authenticator struct {
+	apiKeys     []apiKey
+	signingKeys jwt.VerificationKeySet
+	parser      *jwt.Parser
+}
+
+// apiKey is a configured key and the client it identifies
+type apiKey struct {
+	key  []byte
+	name string
+}
+
+// NewAuthenticator creates an authenticator for the given configuration
+func NewAuthenticator(cfg config.AuthConfig) *Authenticator {
+	a := &Authenticator{}
+	for key, name := range cfg.APIKeys {
+		a.apiKeys = append(a.apiKeys, apiKey{key: []byte(key), name: name})
+	}
+	for _, secret := range cfg.JWTSigningKeys {
+		a.signingKeys.Keys = append(a.signingKeys.Keys, secret)
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "HS384", "HS512"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+	}
+	if cfg.JWTIssuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.JWTIssuer))
+	}
+	if cfg.JWTAudience != "" {
+		options = append(options, jwt.WithAudience(cfg.JWTAudience))
+	}
+	a.parser = jwt.NewParser(options...)
+	return a
+}
+
+// Authenticate identifies the caller of r. An X-API-Key header takes
+// precedence over an Authorization header.
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return a.authenticateAPIKey(key)
+	}
+
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") && strings.TrimSpace(token) != "" {
+		return a.authenticateToken(strings.TrimSpace(token))
+	}
+	return nil, ErrMissingCredentials
+}
+
+// authenticateAPIKey matches key against every configured key in constant time.
+// API keys are issued to trusted backend services, which act as admins.
+func (a *Authenticator) authenticateAPIKey(key string) (*Principal, error) {
+	var principal *Principal
+	for _, k := range a.apiKeys {
+		if subtle.ConstantTimeCompare(k.key, []byte(key)) == 1 {
+			principal = &Principal{Subject: k.name, Scheme: SchemeAPIKey, Roles: []string{RoleAdmin}}
+		}
+	}
+	if principal == nil {
+		return nil, ErrInvalidAPIKey
+	}
+	return principal, nil
+}
+
+// authenticateToken verifies the token's signature and registered claims
+func (a *Authenticator) authenticateToken(token string) (*Principal, error) {
+	if len(a.signingKeys.Keys) == 0 {
+		return nil, ErrInvalidToken
+	}
+
+	var claims tokenClaims
+	_, err := a.parser.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return a.signingKeys, nil
+	})
+	if err != nil {
+		return nil, errors.Join(ErrInvalidToken, err)
+	}
+	if claims.Subject == "" {
+		return nil, errors.Join(ErrInvalidToken, errors.New("token has no subject"))
+	}
+	return &Principal{
+		Subject:    claims.Subject,
+		Scheme:     SchemeBearer,
+		CustomerID: claims.CustomerID,
+		Roles:      claims.Roles,
+	}, nil
+}
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying the authenticated principal
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored by NewContext, or nil
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
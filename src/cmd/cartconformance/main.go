@@ -0,0 +1,66 @@
+// Command cartconformance runs the carttest conformance suite against the cart
+// repository selected by DATABASE_TYPE, using the same configuration sources
+// and flags as the service. Point it at local containers, for example MySQL via DB_HOST
+// or DynamoDB Local via AWS_ENDPOINT_URL, or use DATABASE_TYPE=memory.
+//
+// The suite creates its own carts and leaves them in place, so do not run it
+// against production data.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+
+	"store_product/config"
+	"store_product/migrations"
+	"store_product/repositories"
+	"store_product/repositories/carttest"
+)
+
+func main() {
+	cfg, _, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+	dbType := cfg.DatabaseType
+
+	var repo repositories.CartRepositoryInterface
+	var payments repositories.PaymentRepositoryInterface
+	switch dbType {
+	case "memory":
+		carts := repositories.NewInMemoryCartRepository(cfg.CartTTL)
+		repo, payments = carts, repositories.NewInMemoryPaymentRepository(carts)
+	case "dynamodb":
+		client, closeConns, err := config.InitDynamoDB(cfg.DynamoDB)
+		if err != nil {
+			log.Fatal("Failed to initialize DynamoDB:", err)
+		}
+		defer closeConns()
+		tables := cfg.DynamoDB.Tables
+		repo = repositories.NewDynamoDBCartRepository(client, tables.Carts, tables.Orders, cfg.CartTTL)
+		payments = repositories.NewDynamoDBPaymentRepository(client, tables.Payments, tables.Carts)
+	default:
+		db, err := config.InitDB(cfg.MySQL)
+		if err != nil {
+			log.Fatal("Failed to connect to MySQL database:", err)
+		}
+		defer db.Close()
+		if err := migrations.Up(context.Background(), db); err != nil {
+			log.Fatal("Failed to migrate MySQL database:", err)
+		}
+		repo = repositories.NewMySQLCartRepository(db)
+		payments = repositories.NewMySQLPaymentRepository(db)
+	}
+
+	log.Printf("Running cart repository conformance suite against %s", dbType)
+	if err := carttest.TestRepository(context.Background(), repo, payments); err != nil {
+		log.Fatalf("Cart repository does not conform:\n%v", err)
+	}
+	log.Println("Cart repository conforms")
+}
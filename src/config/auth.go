@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AuthConfig holds the credentials accepted by the authentication middleware
+type AuthConfig struct {
+	APIKeys        map[string]string // key -> client name
+	JWTSigningKeys [][]byte          // HMAC secrets; several allow key rotation
+	JWTIssuer      string            // required "iss" claim, if set
+	JWTAudience    string            // required "aud" claim, if set
+}
+
+// Configured reports whether any credentials are accepted. Without API keys
+// or signing keys every protected route rejects its requests.
+func (c AuthConfig) Configured() bool {
+	return len(c.APIKeys) > 0 || len(c.JWTSigningKeys) > 0
+}
+
+// parseAPIKeys reads comma separated name:key pairs, e.g.
+// "checkout:s3cr3t,ops:0th3r". API keys belong to trusted backend services
+// and carry the admin role. The entries are not quoted in errors, as they
+// hold the keys.
+func parseAPIKeys(value string) (map[string]string, error) {
+	keys := make(map[string]string)
+	for i, entry := range splitList(value) {
+		name, key, ok := strings.Cut(entry, ":")
+		name, key = strings.TrimSpace(name), strings.TrimSpace(key)
+		if !ok || name == "" || key == "" {
+			return nil, fmt.Errorf("entry %d is not a name:key pair", i+1)
+		}
+		keys[key] = name
+	}
+	return keys, nil
+}
+
+// splitList splits a comma separated value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
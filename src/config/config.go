@@ -0,0 +1,185 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Config is the complete service configuration. It is loaded and validated
+// once at startup by Load and passed down from main; nothing else reads the
+// environment.
+type Config struct {
+	Server         ServerConfig
+	DatabaseType   string        // "mysql", "dynamodb" or "memory"
+	MigrateOnStart bool          // apply pending MySQL migrations at startup
+	CartTTL        time.Duration // expiry of new carts on DynamoDB and in memory
+	LogLevel       slog.Level
+	TracesExporter string // "none", "stdout" or "otlp"; tracing.Init knows what each means
+	MySQL          MySQLConfig
+	DynamoDB       DynamoDBConfig
+	Auth           AuthConfig
+	Payments       PaymentsConfig
+}
+
+// ServerConfig holds the HTTP server settings
+type ServerConfig struct {
+	Port            int
+	RequestTimeout  time.Duration // deadline applied to handler and database work
+	ShutdownDelay   time.Duration // time serving on while the health check reports draining
+	ShutdownTimeout time.Duration // time in-flight requests get to finish at shutdown
+}
+
+// Addr returns the address the server listens on
+func (c ServerConfig) Addr() string {
+	return fmt.Sprintf(":%d", c.Port)
+}
+
+// MySQLConfig holds the MySQL connection and pool settings
+type MySQLConfig struct {
+	Host            string
+	Port            int
+	User            string
+	Password        string
+	Name            string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// DynamoDBConfig holds the DynamoDB region and table names
+type DynamoDBConfig struct {
+	Region string
+	Tables DynamoDBTables
+}
+
+// PaymentsConfig holds the settings of the local payment processor
+type PaymentsConfig struct {
+	DeclinePrefixes []string // card number prefixes that are always declined
+}
+
+// Load builds the configuration from, in increasing precedence: the defaults,
+// a config file, the environment and command line flags. args are the command
+// line arguments without the program name; those left after the flags are
+// returned. Every invalid or missing value is reported in a single error.
+//
+// The config file is named by the -config flag or CONFIG_FILE. A .yaml or
+// .yml file maps setting names as used for flags, e.g. "db-host", to values;
+// any other file is read as a .env file. Without one, a .env file in the
+// working directory is read if present, as in local development. Variables
+// from a .env file never override the environment and are also visible to
+// the AWS SDK, so it can hold AWS_ENDPOINT_URL or credentials.
+func Load(args []string) (*Config, []string, error) {
+	fs := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "YAML or .env file to read settings from (CONFIG_FILE)")
+
+	var overrides []settingValue
+	for _, s := range settings {
+		if s.secret {
+			continue
+		}
+		fs.Func(s.key(), s.flagUsage(), func(value string) error {
+			overrides = append(overrides, settingValue{setting: s, value: value, name: "-" + s.key()})
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	var values []settingValue
+	for _, s := range settings {
+		if s.def != "" {
+			values = append(values, settingValue{setting: s, value: s.def, name: s.env})
+		}
+	}
+
+	// Problems in the file are reported along with those of the values
+	fileValues, fileErr := readConfigFile(*configFile)
+	values = append(values, fileValues...)
+	values = append(values, environmentValues()...)
+	values = append(values, overrides...)
+
+	cfg := &Config{}
+	var errs []error
+	if fileErr != nil {
+		errs = append(errs, fileErr)
+	}
+	for _, v := range values {
+		if err := v.apply(cfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, fs.Args(), nil
+}
+
+// Validate checks the ranges of the settings and those required by the
+// selected database type
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validPort(c.Server.Port), "PORT must be between 1 and 65535, got %d", c.Server.Port)
+	check(c.Server.RequestTimeout > 0, "REQUEST_TIMEOUT must be positive")
+	check(c.Server.ShutdownDelay >= 0, "SHUTDOWN_DELAY must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT must be positive")
+	check(c.CartTTL > 0, "CART_TTL must be positive")
+
+	switch c.TracesExporter {
+	case "none", "stdout", "otlp":
+	default:
+		check(false, "OTEL_TRACES_EXPORTER must be none, stdout or otlp, got %q", c.TracesExporter)
+	}
+
+	switch c.DatabaseType {
+	case "mysql":
+		m := c.MySQL
+		check(m.Host != "", "DB_HOST is required when DATABASE_TYPE is mysql")
+		check(m.Port != 0, "DB_PORT is required when DATABASE_TYPE is mysql")
+		check(m.Port == 0 || validPort(m.Port), "DB_PORT must be between 1 and 65535, got %d", m.Port)
+		check(m.User != "", "DB_USER is required when DATABASE_TYPE is mysql")
+		check(m.Password != "", "DB_PASSWORD is required when DATABASE_TYPE is mysql")
+		check(m.Name != "", "DB_NAME is required when DATABASE_TYPE is mysql")
+		check(m.MaxOpenConns >= 0, "DB_MAX_OPEN_CONNS must not be negative")
+		check(m.MaxIdleConns >= 0, "DB_MAX_IDLE_CONNS must not be negative")
+		check(m.MaxOpenConns == 0 || m.MaxIdleConns <= m.MaxOpenConns,
+			"DB_MAX_IDLE_CONNS (%d) must not exceed DB_MAX_OPEN_CONNS (%d)", m.MaxIdleConns, m.MaxOpenConns)
+		check(m.ConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME must not be negative")
+		check(m.ConnMaxIdleTime >= 0, "DB_CONN_MAX_IDLE_TIME must not be negative")
+	case "dynamodb":
+		t := c.DynamoDB.Tables
+		check(c.DynamoDB.Region != "", "AWS_REGION is required when DATABASE_TYPE is dynamodb")
+		check(t.Carts != "", "DYNAMODB_TABLE_NAME is required when DATABASE_TYPE is dynamodb")
+		check(t.Orders != "", "DYNAMODB_ORDERS_TABLE_NAME is required when DATABASE_TYPE is dynamodb")
+		check(t.Inventory != "", "DYNAMODB_INVENTORY_TABLE_NAME is required when DATABASE_TYPE is dynamodb")
+		check(t.Payments != "", "DYNAMODB_PAYMENTS_TABLE_NAME is required when DATABASE_TYPE is dynamodb")
+		check(t.Products != "", "DYNAMODB_PRODUCTS_TABLE_NAME is required when DATABASE_TYPE is dynamodb")
+		check(t.ProductSKUs != "", "DYNAMODB_PRODUCT_SKUS_TABLE_NAME is required when DATABASE_TYPE is dynamodb")
+	case "memory":
+	default:
+		check(false, "DATABASE_TYPE must be mysql, dynamodb or memory, got %q", c.DatabaseType)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validPort reports whether port is a usable TCP port number
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
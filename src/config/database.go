@@ -3,151 +3,98 @@ package config
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
-	"log"
-	"os"
-	"time"
+	"log/slog"
+	"net/http"
 
+	"store_product/metrics"
+	"store_product/tracing"
+
+	"github.com/XSAM/otelsql"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	_ "github.com/go-sql-driver/mysql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
-// DBConfig holds database configuration
-type DBConfig struct {
-	Type           string // "mysql" or "dynamodb"
-	MySQLDB        *sql.DB
-	DynamoDBClient *dynamodb.Client
-	DynamoDBTable  string
-	AWSRegion      string
-}
-
-// GetDatabaseType returns the configured database type
-func GetDatabaseType() string {
-	dbType := os.Getenv("DATABASE_TYPE")
-	if dbType == "" {
-		dbType = "mysql" // default to MySQL for backward compatibility
-	}
-	return dbType
+// DynamoDBTables holds the names of the DynamoDB tables used by the service
+type DynamoDBTables struct {
+	Carts       string
+	Orders      string
+	Inventory   string
+	Payments    string
+	Products    string
+	ProductSKUs string
 }
 
-func InitDB() (*sql.DB, error) {
-	required := []string{"DB_USER", "DB_PASSWORD", "DB_HOST", "DB_PORT", "DB_NAME"}
-	values := make(map[string]string, len(required))
-	var missing []string
-
-	for _, k := range required {
-		v, ok := os.LookupEnv(k)
-		if !ok || v == "" {
-			missing = append(missing, k)
-			continue
-		}
-		values[k] = v
-	}
-
-	if len(missing) > 0 {
-		return nil, fmt.Errorf("missing required environment variables: %v", missing)
-	}
-
-	// Build DSN from validated environment variables
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
-		values["DB_USER"],
-		values["DB_PASSWORD"],
-		values["DB_HOST"],
-		values["DB_PORT"],
-		values["DB_NAME"],
+// InitDB connects to MySQL. The schema is managed by the migrations package.
+func InitDB(cfg MySQLConfig) (*sql.DB, error) {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
+		cfg.User,
+		cfg.Password,
+		cfg.Host,
+		cfg.Port,
+		cfg.Name,
 	)
 
-	db, err := sql.Open("mysql", dsn)
+	// Every statement gets a span below the repository operation that ran it.
+	// Rows and session resets would only add noise to each trace.
+	db, err := otelsql.Open("mysql", dsn,
+		otelsql.WithAttributes(semconv.DBSystemMySQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			SpanFilter: func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+				return tracing.InSpan(ctx)
+			},
+		}),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
 	// Connection pool configuration
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(10)
-	db.SetConnMaxLifetime(5 * time.Minute)
-	db.SetConnMaxIdleTime(2 * time.Minute)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
 	// Verify connection
 	if err := db.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Println("Successfully connected to database")
-
-	// Initialize schema
-	if err := initSchema(db); err != nil {
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
-	}
-
-	log.Println("Database schema initialized")
+	slog.Info("connected to MySQL", "host", cfg.Host, "database", cfg.Name)
 	return db, nil
 }
 
-func initSchema(db *sql.DB) error {
-	// Create shopping_carts table
-	createCartsTable := `
-	CREATE TABLE IF NOT EXISTS shopping_carts (
-		cart_id INT AUTO_INCREMENT PRIMARY KEY,
-		customer_id INT NOT NULL,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-		INDEX idx_customer_id (customer_id)
-	) ENGINE=InnoDB`
-
-	if _, err := db.Exec(createCartsTable); err != nil {
-		return fmt.Errorf("failed to create shopping_carts table: %w", err)
-	}
-
-	// Create cart_items table
-	createItemsTable := `
-	CREATE TABLE IF NOT EXISTS cart_items (
-		item_id INT AUTO_INCREMENT PRIMARY KEY,
-		cart_id INT NOT NULL,
-		product_id INT NOT NULL,
-		quantity INT NOT NULL DEFAULT 1,
-		added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-		FOREIGN KEY (cart_id) REFERENCES shopping_carts(cart_id) ON DELETE CASCADE,
-		UNIQUE KEY unique_cart_product (cart_id, product_id),
-		INDEX idx_cart_id (cart_id),
-		CHECK (quantity >= 0)
-	) ENGINE=InnoDB`
-
-	if _, err := db.Exec(createItemsTable); err != nil {
-		return fmt.Errorf("failed to create cart_items table: %w", err)
-	}
-
-	log.Println("Database schema initialized successfully")
-	return nil
-}
-
-// InitDynamoDB initializes DynamoDB client
-func InitDynamoDB() (*dynamodb.Client, string, error) {
-	// Check required environment variables
-	tableName := os.Getenv("DYNAMODB_TABLE_NAME")
-	if tableName == "" {
-		return nil, "", fmt.Errorf("DYNAMODB_TABLE_NAME environment variable is required")
-	}
-
-	region := os.Getenv("AWS_REGION")
-	if region == "" {
-		region = "us-west-2" // default region
-		log.Printf("AWS_REGION not set, using default: %s", region)
+// InitDynamoDB initializes the DynamoDB client for the configured region. The
+// client holds no connections open besides idle keep-alive ones; the returned
+// function closes those once the client is no longer used.
+func InitDynamoDB(cfg DynamoDBConfig) (*dynamodb.Client, func(), error) {
+	// The SDK's default transport, kept so its idle connections can be
+	// closed. Like the SDK's own client, it does not follow redirects.
+	transport := awshttp.NewBuildableClient().GetTransport()
+	httpClient := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
 	}
 
 	// Load AWS configuration
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithRegion(region),
-	)
+	awsCfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(cfg.Region), config.WithHTTPClient(httpClient))
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to load AWS config: %w", err)
+		return nil, nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	// Create DynamoDB client
-	client := dynamodb.NewFromConfig(cfg)
+	// Create DynamoDB client, tracing each operation and recording its consumed capacity
+	client := dynamodb.NewFromConfig(awsCfg, metrics.RecordConsumedCapacity, tracing.TraceDynamoDB)
 
-	log.Printf("Successfully initialized DynamoDB client for table: %s in region: %s", tableName, region)
-	return client, tableName, nil
+	t := cfg.Tables
+	slog.Info("initialized DynamoDB client", "region", cfg.Region,
+		"tables", []string{t.Carts, t.Orders, t.Inventory, t.Payments, t.Products, t.ProductSKUs})
+	return client, transport.CloseIdleConnections, nil
 }
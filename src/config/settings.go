@@ -0,0 +1,299 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// setting is one configuration value and the names it goes by in each source
+type setting struct {
+	env        string // environment variable, also the name in .env files
+	def        string // default value, applied like any other source
+	usage      string
+	secret     bool // not accepted as a flag, where it would show in the process list
+	allowEmpty bool // an empty environment variable is a value rather than unset
+	set        func(c *Config, value string) error
+}
+
+// key returns the setting's flag and YAML name, e.g. "db-host" for DB_HOST
+func (s setting) key() string {
+	return strings.ToLower(strings.ReplaceAll(s.env, "_", "-"))
+}
+
+// flagUsage returns the flag help text, naming the variable and default
+func (s setting) flagUsage() string {
+	if s.def == "" {
+		return fmt.Sprintf("%s (%s)", s.usage, s.env)
+	}
+	return fmt.Sprintf("%s (%s, default %s)", s.usage, s.env, s.def)
+}
+
+// settings lists every configuration value the service reads
+var settings = []setting{
+	{env: "PORT", def: "8080", usage: "HTTP listen port",
+		set: intValue(func(c *Config) *int { return &c.Server.Port })},
+	{env: "REQUEST_TIMEOUT", def: "10s", usage: "deadline for handler and database work of a request",
+		set: durationValue(func(c *Config) *time.Duration { return &c.Server.RequestTimeout })},
+	{env: "SHUTDOWN_DELAY", def: "15s", usage: "time to keep serving after a stop signal so the load balancer can drain the task; must cover the ALB health check's interval times its unhealthy threshold, plus one interval",
+		set: durationValue(func(c *Config) *time.Duration { return &c.Server.ShutdownDelay })},
+	{env: "SHUTDOWN_TIMEOUT", def: "10s", usage: "time in-flight requests get to finish at shutdown; with the delay keep it below the 30s ECS waits",
+		set: durationValue(func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout })},
+	{env: "LOG_LEVEL", def: "info", usage: "minimum log level: debug, info, warn or error",
+		set: func(c *Config, value string) error {
+			if err := c.LogLevel.UnmarshalText([]byte(value)); err != nil {
+				return errors.New("must be debug, info, warn or error")
+			}
+			return nil
+		}},
+	{env: "OTEL_TRACES_EXPORTER", def: "none", usage: "trace exporter: none, stdout or otlp (configured by OTEL_EXPORTER_OTLP_*)",
+		set: stringValue(func(c *Config) *string { return &c.TracesExporter })},
+
+	{env: "DATABASE_TYPE", def: "mysql", usage: "storage backend: mysql, dynamodb or memory",
+		set: stringValue(func(c *Config) *string { return &c.DatabaseType })},
+	{env: "MIGRATE_ON_START", def: "true", usage: `apply pending MySQL migrations at startup; disable to run "migrate up" as a deployment step`,
+		set: boolValue(func(c *Config) *bool { return &c.MigrateOnStart })},
+	{env: "CART_TTL", def: "24h", usage: "expiry of new carts on DynamoDB and in memory",
+		set: durationValue(func(c *Config) *time.Duration { return &c.CartTTL })},
+
+	{env: "DB_HOST", usage: "MySQL host",
+		set: stringValue(func(c *Config) *string { return &c.MySQL.Host })},
+	{env: "DB_PORT", usage: "MySQL port",
+		set: intValue(func(c *Config) *int { return &c.MySQL.Port })},
+	{env: "DB_USER", usage: "MySQL user",
+		set: stringValue(func(c *Config) *string { return &c.MySQL.User })},
+	{env: "DB_PASSWORD", usage: "MySQL password", secret: true,
+		set: stringValue(func(c *Config) *string { return &c.MySQL.Password })},
+	{env: "DB_NAME", usage: "MySQL database name",
+		set: stringValue(func(c *Config) *string { return &c.MySQL.Name })},
+	{env: "DB_MAX_OPEN_CONNS", def: "25", usage: "maximum open MySQL connections; 0 is unlimited",
+		set: intValue(func(c *Config) *int { return &c.MySQL.MaxOpenConns })},
+	{env: "DB_MAX_IDLE_CONNS", def: "10", usage: "maximum idle MySQL connections",
+		set: intValue(func(c *Config) *int { return &c.MySQL.MaxIdleConns })},
+	{env: "DB_CONN_MAX_LIFETIME", def: "5m", usage: "maximum age of a MySQL connection; 0 keeps them forever",
+		set: durationValue(func(c *Config) *time.Duration { return &c.MySQL.ConnMaxLifetime })},
+	{env: "DB_CONN_MAX_IDLE_TIME", def: "2m", usage: "maximum idle time of a MySQL connection; 0 keeps them forever",
+		set: durationValue(func(c *Config) *time.Duration { return &c.MySQL.ConnMaxIdleTime })},
+
+	{env: "AWS_REGION", def: "us-west-2", usage: "AWS region of the DynamoDB tables",
+		set: stringValue(func(c *Config) *string { return &c.DynamoDB.Region })},
+	{env: "DYNAMODB_TABLE_NAME", usage: "DynamoDB carts table",
+		set: stringValue(func(c *Config) *string { return &c.DynamoDB.Tables.Carts })},
+	{env: "DYNAMODB_ORDERS_TABLE_NAME", usage: "DynamoDB orders table",
+		set: stringValue(func(c *Config) *string { return &c.DynamoDB.Tables.Orders })},
+	{env: "DYNAMODB_INVENTORY_TABLE_NAME", usage: "DynamoDB inventory table",
+		set: stringValue(func(c *Config) *string { return &c.DynamoDB.Tables.Inventory })},
+	{env: "DYNAMODB_PAYMENTS_TABLE_NAME", usage: "DynamoDB payments table",
+		set: stringValue(func(c *Config) *string { return &c.DynamoDB.Tables.Payments })},
+	{env: "DYNAMODB_PRODUCTS_TABLE_NAME", usage: "DynamoDB products table",
+		set: stringValue(func(c *Config) *string { return &c.DynamoDB.Tables.Products })},
+	{env: "DYNAMODB_PRODUCT_SKUS_TABLE_NAME", usage: "DynamoDB product SKUs table",
+		set: stringValue(func(c *Config) *string { return &c.DynamoDB.Tables.ProductSKUs })},
+
+	{env: "API_KEYS", usage: "comma separated name:key pairs of service clients", secret: true,
+		set: func(c *Config, value string) error {
+			keys, err := parseAPIKeys(value)
+			c.Auth.APIKeys = keys
+			return err
+		}},
+	{env: "JWT_SIGNING_KEYS", usage: "comma separated HMAC secrets for bearer tokens", secret: true,
+		set: func(c *Config, value string) error {
+			c.Auth.JWTSigningKeys = nil
+			for _, secret := range splitList(value) {
+				c.Auth.JWTSigningKeys = append(c.Auth.JWTSigningKeys, []byte(secret))
+			}
+			return nil
+		}},
+	{env: "JWT_ISSUER", usage: "required iss claim of bearer tokens",
+		set: stringValue(func(c *Config) *string { return &c.Auth.JWTIssuer })},
+	{env: "JWT_AUDIENCE", usage: "required aud claim of bearer tokens",
+		set: stringValue(func(c *Config) *string { return &c.Auth.JWTAudience })},
+
+	// The default is the well-known "card declined" test number
+	{env: "PAYMENT_DECLINE_PREFIXES", def: "4000000000000002", usage: "comma separated card number prefixes the local payment processor declines",
+		allowEmpty: true,
+		set: func(c *Config, value string) error {
+			c.Payments.DeclinePrefixes = splitList(value)
+			return nil
+		}},
+}
+
+// settingValue is a value for a setting read from one source
+type settingValue struct {
+	setting setting
+	value   string
+	name    string // the setting's name in its source, for error messages
+	file    string // the config file the value came from, if any
+}
+
+// apply stores the value in cfg. Secret values are left out of errors.
+func (v settingValue) apply(cfg *Config) error {
+	err := v.setting.set(cfg, v.value)
+	if err == nil {
+		return nil
+	}
+
+	where := ""
+	if v.file != "" {
+		where = " in " + v.file
+	}
+	if v.setting.secret {
+		return fmt.Errorf("invalid %s%s: %w", v.name, where, err)
+	}
+	return fmt.Errorf("invalid %s %q%s: %w", v.name, v.value, where, err)
+}
+
+// environmentValues returns the settings present in the environment
+func environmentValues() []settingValue {
+	var values []settingValue
+	for _, s := range settings {
+		value, ok := os.LookupEnv(s.env)
+		if !ok || (value == "" && !s.allowEmpty) {
+			continue
+		}
+		values = append(values, settingValue{setting: s, value: value, name: s.env})
+	}
+	return values
+}
+
+// readConfigFile returns the settings of a YAML file. A .env file is loaded
+// into the environment instead, without overriding variables already set.
+// With no path, ./.env is loaded if it exists.
+func readConfigFile(path string) ([]settingValue, error) {
+	if path == "" {
+		if _, err := os.Stat(".env"); err != nil {
+			return nil, nil
+		}
+		path = ".env"
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return readYAMLFile(path)
+	default:
+		if err := godotenv.Load(path); err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		return nil, nil
+	}
+}
+
+// readYAMLFile reads a mapping of setting keys to scalars or lists of scalars
+func readYAMLFile(path string) ([]settingValue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	byKey := make(map[string]setting, len(settings))
+	for _, s := range settings {
+		byKey[s.key()] = s
+	}
+
+	// Report problems in a stable order
+	keys := make([]string, 0, len(doc))
+	for key := range doc {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var values []settingValue
+	var errs []error
+	for _, key := range keys {
+		s, ok := byKey[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown setting %q in %s", key, path))
+			continue
+		}
+		value, err := yamlScalars(doc[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s in %s: %w", key, path, err))
+			continue
+		}
+		values = append(values, settingValue{setting: s, value: value, name: key, file: path})
+	}
+	return values, errors.Join(errs...)
+}
+
+// yamlScalars renders a YAML scalar as a string and a list of scalars as a
+// comma separated string, the form list settings take in the environment
+func yamlScalars(node interface{}) (string, error) {
+	switch v := node.(type) {
+	case nil:
+		return "", nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			if _, isList := item.([]interface{}); isList {
+				return "", errors.New("lists may not be nested")
+			}
+			s, err := yamlScalars(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}:
+		return "", errors.New("expected a value or a list, not a mapping")
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// stringValue stores the value as is
+func stringValue(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+// intValue parses a decimal integer
+func intValue(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+// boolValue parses true/false, 1/0 and the other forms strconv accepts
+func boolValue(field func(*Config) *bool) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+// durationValue parses a Go duration such as "500ms" or "5m"
+func durationValue(field func(*Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.New(`must be a duration such as "5s" or "2m"`)
+		}
+		*field(c) = d
+		return nil
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"store_product/auth"
+	"store_product/models"
+	"store_product/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authorizeCustomer writes a 403 response and returns false unless the
+// authenticated caller may act for the given customer
+func authorizeCustomer(c *gin.Context, customerID int) bool {
+	if auth.FromContext(c.Request.Context()).CanActFor(customerID) {
+		return true
+	}
+	c.JSON(http.StatusForbidden, models.ErrorResponse{
+		Error:   "FORBIDDEN",
+		Message: "Access denied",
+		Details: "The caller may not act for this customer",
+	})
+	return false
+}
+
+// loadOwnedCart fetches a cart the authenticated caller may access. Carts of
+// other customers are reported as not found, so their IDs cannot be probed;
+// callers without a customer identity get a 403. On failure the response has
+// been written and a nil cart is returned.
+//
+// The returned logger is annotated with the cart ID and, once the cart is
+// found, its owner's customer ID, whether the caller is that customer, an
+// admin or an API key.
+func loadOwnedCart(c *gin.Context, repo repositories.CartRepositoryInterface, logger *slog.Logger, cartID models.CartID) (*models.ShoppingCart, *slog.Logger) {
+	logger = logger.With("cart_id", cartID)
+	principal := auth.FromContext(c.Request.Context())
+	if !principal.IsAdmin() && (principal == nil || principal.CustomerID == 0) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "FORBIDDEN",
+			Message: "Access denied",
+			Details: "Only customers and admins may access shopping carts",
+		})
+		return nil, logger
+	}
+
+	cart, err := repo.GetByID(c.Request.Context(), cartID)
+	if err != nil {
+		respondCartError(c, logger, err, "Failed to fetch cart")
+		return nil, logger
+	}
+	if cart == nil || !principal.CanActFor(cart.CustomerID) {
+		respondCartError(c, logger, repositories.ErrCartNotFound, "Shopping cart not found")
+		return nil, logger
+	}
+	return cart, logger.With("customer_id", cart.CustomerID)
+}
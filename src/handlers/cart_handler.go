@@ -1,7 +1,9 @@
 package handlers
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strconv"
 
@@ -13,12 +15,14 @@ import (
 
 // CartHandler handles shopping cart requests
 type CartHandler struct {
-	repo repositories.CartRepositoryInterface
+	repo        repositories.CartRepositoryInterface
+	productRepo repositories.ProductRepositoryInterface
+	logger      *slog.Logger
 }
 
 // NewCartHandler creates a new cart handler
-func NewCartHandler(repo repositories.CartRepositoryInterface) *CartHandler {
-	return &CartHandler{repo: repo}
+func NewCartHandler(repo repositories.CartRepositoryInterface, productRepo repositories.ProductRepositoryInterface, logger *slog.Logger) *CartHandler {
+	return &CartHandler{repo: repo, productRepo: productRepo, logger: logger}
 }
 
 // Create handles POST /shopping-carts
@@ -32,84 +36,131 @@ func (h *CartHandler) Create(c *gin.Context) {
 		})
 		return
 	}
-
-	cartID, err := h.repo.Create(req.CustomerID)
-	if err != nil {
-		log.Printf("Error creating cart: %v", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "DATABASE_ERROR",
-			Message: "Failed to create cart",
-		})
+	if !authorizeCustomer(c, req.CustomerID) {
 		return
 	}
 
-	// Handle both int (MySQL) and string (DynamoDB) cart IDs
-	var response interface{}
-	switch v := cartID.(type) {
-	case int:
-		response = models.CreateCartResponse{ShoppingCartID: v}
-	case string:
-		response = map[string]string{"shopping_cart_id": v}
-	default:
-		log.Printf("Unexpected cart ID type: %T", cartID)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "INTERNAL_ERROR",
-			Message: "Unexpected cart ID type",
-		})
+	cartID, err := h.repo.Create(c.Request.Context(), req.CustomerID)
+	if err != nil {
+		respondDatabaseError(c, h.logger.With("customer_id", req.CustomerID), err, "Failed to create cart")
 		return
 	}
 
-	c.JSON(http.StatusCreated, response)
+	c.JSON(http.StatusCreated, models.CreateCartResponse{ShoppingCartID: cartID})
 }
 
 // GetByID handles GET /shopping-carts/:id
 func (h *CartHandler) GetByID(c *gin.Context) {
-	idStr := c.Param("id")
+	cartID := cartIDParam(c)
+	cart, logger := loadOwnedCart(c, h.repo, h.logger, cartID)
+	if cart == nil {
+		return
+	}
 
-	// Try to parse as int first (MySQL), if it fails, treat as string (DynamoDB UUID)
-	var cartID interface{}
-	if id, err := strconv.Atoi(idStr); err == nil && id > 0 {
-		cartID = id
-	} else {
-		// Assume it's a UUID string for DynamoDB
-		cartID = idStr
+	prices, err := currentPrices(c.Request.Context(), h.productRepo, cart.Items)
+	if err != nil {
+		respondDatabaseError(c, logger, err, "Failed to fetch product prices")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewCartResponse(*cart, prices))
+}
+
+// currentPrices looks up the current price of each product in the items.
+// Products that no longer exist or have no price are left out.
+func currentPrices(ctx context.Context, productRepo repositories.ProductRepositoryInterface, items []models.CartItem) (map[int]models.Money, error) {
+	prices := make(map[int]models.Money, len(items))
+	if len(items) == 0 {
+		return prices, nil
 	}
 
-	cart, err := h.repo.GetByID(cartID)
+	ids := make([]int, len(items))
+	for i, item := range items {
+		ids[i] = item.ProductID
+	}
+	products, err := productRepo.GetByIDs(ctx, ids)
 	if err != nil {
-		log.Printf("Error fetching cart: %v", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "DATABASE_ERROR",
-			Message: "Failed to fetch cart",
+		return nil, err
+	}
+	for _, product := range products {
+		if product.Price != nil {
+			prices[product.ProductID] = *product.Price
+		}
+	}
+	return prices, nil
+}
+
+// AddItem handles POST /shopping-carts/:id/items
+func (h *CartHandler) AddItem(c *gin.Context) {
+	cartID := cartIDParam(c)
+
+	var req models.AddItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_INPUT",
+			Message: "Invalid request body",
+			Details: err.Error(),
 		})
 		return
 	}
 
+	// Check that the cart exists and belongs to the caller
+	cart, logger := loadOwnedCart(c, h.repo, h.logger, cartID)
 	if cart == nil {
+		return
+	}
+
+	// Check if product exists; its current price is snapshotted on the item
+	product, err := h.productRepo.GetByID(c.Request.Context(), req.ProductID)
+	if err != nil {
+		respondDatabaseError(c, logger, err, "Failed to verify product")
+		return
+	}
+	if product == nil {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Error:   "NOT_FOUND",
-			Message: "Shopping cart not found",
+			Message: "Product not found",
+			Details: "The requested product does not exist",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, cart)
+	// Add item to cart
+	if err := h.repo.AddItem(c.Request.Context(), cartID, req.ProductID, req.Quantity, product.Price); err != nil {
+		respondCartError(c, logger, err, "Failed to add item to cart")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
 }
 
-// AddItem handles POST /shopping-carts/:id/items
-func (h *CartHandler) AddItem(c *gin.Context) {
-	idStr := c.Param("id")
+// Checkout handles POST /shopping-carts/:id/checkout
+func (h *CartHandler) Checkout(c *gin.Context) {
+	cartID := cartIDParam(c)
+	cart, logger := loadOwnedCart(c, h.repo, h.logger, cartID)
+	if cart == nil {
+		return
+	}
 
-	// Try to parse as int first (MySQL), if it fails, treat as string (DynamoDB UUID)
-	var cartID interface{}
-	if id, err := strconv.Atoi(idStr); err == nil && id > 0 {
-		cartID = id
-	} else {
-		// Assume it's a UUID string for DynamoDB
-		cartID = idStr
+	orderID, err := h.repo.Checkout(c.Request.Context(), cartID)
+	if err != nil {
+		respondCartError(c, logger, err, "Failed to checkout cart")
+		return
 	}
 
-	var req models.AddItemRequest
+	c.JSON(http.StatusOK, models.CheckoutResponse{OrderID: orderID})
+}
+
+// UpdateItem handles PUT and PATCH /shopping-carts/:id/items/:productId
+func (h *CartHandler) UpdateItem(c *gin.Context) {
+	cartID := cartIDParam(c)
+
+	productID, ok := productIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateItemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "INVALID_INPUT",
@@ -119,33 +170,197 @@ func (h *CartHandler) AddItem(c *gin.Context) {
 		return
 	}
 
-	// Check if cart exists
-	exists, err := h.repo.Exists(cartID)
-	if err != nil {
-		log.Printf("Error checking cart existence: %v", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "DATABASE_ERROR",
-			Message: "Failed to verify cart",
-		})
+	cart, logger := loadOwnedCart(c, h.repo, h.logger, cartID)
+	if cart == nil {
 		return
 	}
-	if !exists {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{
-			Error:   "NOT_FOUND",
-			Message: "Shopping cart not found",
+	if err := h.repo.UpdateItemQuantity(c.Request.Context(), cartID, productID, req.Quantity); err != nil {
+		respondCartError(c, logger, err, "Failed to update cart item")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveItem handles DELETE /shopping-carts/:id/items/:productId
+func (h *CartHandler) RemoveItem(c *gin.Context) {
+	cartID := cartIDParam(c)
+
+	productID, ok := productIDParam(c)
+	if !ok {
+		return
+	}
+
+	cart, logger := loadOwnedCart(c, h.repo, h.logger, cartID)
+	if cart == nil {
+		return
+	}
+	if err := h.repo.RemoveItem(c.Request.Context(), cartID, productID); err != nil {
+		respondCartError(c, logger, err, "Failed to remove cart item")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ClearItems handles DELETE /shopping-carts/:id/items
+func (h *CartHandler) ClearItems(c *gin.Context) {
+	cartID := cartIDParam(c)
+	cart, logger := loadOwnedCart(c, h.repo, h.logger, cartID)
+	if cart == nil {
+		return
+	}
+
+	if err := h.repo.ClearItems(c.Request.Context(), cartID); err != nil {
+		respondCartError(c, logger, err, "Failed to clear cart")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Delete handles DELETE /shopping-carts/:id
+func (h *CartHandler) Delete(c *gin.Context) {
+	cartID := cartIDParam(c)
+	cart, logger := loadOwnedCart(c, h.repo, h.logger, cartID)
+	if cart == nil {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), cartID); err != nil {
+		respondCartError(c, logger, err, "Failed to delete cart")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// defaultCartPageSize is used when GET /customers/:customerId/shopping-carts has no limit
+const defaultCartPageSize = 20
+
+// ListByCustomer handles GET /customers/:customerId/shopping-carts
+func (h *CartHandler) ListByCustomer(c *gin.Context) {
+	customerID, err := strconv.Atoi(c.Param("customerId"))
+	if err != nil || customerID < 1 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_INPUT",
+			Message: "The provided input data is invalid",
+			Details: "Customer ID must be a positive integer",
 		})
 		return
 	}
+	if !authorizeCustomer(c, customerID) {
+		return
+	}
 
-	// Add item to cart
-	if err := h.repo.AddItem(cartID, req.ProductID, req.Quantity); err != nil {
-		log.Printf("Error adding item to cart: %v", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "DATABASE_ERROR",
-			Message: "Failed to add item to cart",
+	var query models.ListCartsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_INPUT",
+			Message: "Invalid query parameters",
+			Details: err.Error(),
 		})
 		return
 	}
+	if query.Limit == 0 {
+		query.Limit = defaultCartPageSize
+	}
 
-	c.Status(http.StatusNoContent)
+	carts, next, err := h.repo.GetByCustomerID(c.Request.Context(), customerID, repositories.CartListOptions{
+		Limit:        query.Limit,
+		Cursor:       query.NextToken,
+		IncludeItems: query.IncludeItems,
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "INVALID_INPUT",
+				Message: "Invalid query parameters",
+				Details: "next_token is not a valid continuation token",
+			})
+			return
+		}
+		respondDatabaseError(c, h.logger.With("customer_id", customerID), err, "Failed to fetch customer carts")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ListCartsResponse{
+		ShoppingCarts: carts,
+		NextToken:     next,
+	})
+}
+
+// cartIDParam reads the :id path parameter. Whether the ID is well formed
+// depends on the backend, so the repository validates it (ErrInvalidCartID).
+func cartIDParam(c *gin.Context) models.CartID {
+	return models.CartID(c.Param("id"))
+}
+
+// productIDParam reads the :productId path parameter, writing a 400 response
+// and returning false when it is not a positive integer
+func productIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("productId"))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_INPUT",
+			Message: "The provided input data is invalid",
+			Details: "Product ID must be a positive integer",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondCartError maps cart repository errors to API error responses
+func respondCartError(c *gin.Context, logger *slog.Logger, err error, message string) {
+	switch {
+	case errors.Is(err, repositories.ErrInvalidCartID):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_INPUT",
+			Message: "The provided input data is invalid",
+			Details: "Shopping cart ID is not valid",
+		})
+	case errors.Is(err, repositories.ErrCartNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT_FOUND",
+			Message: "Shopping cart not found",
+			Details: "The requested shopping cart does not exist",
+		})
+	case errors.Is(err, repositories.ErrItemNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT_FOUND",
+			Message: "Cart item not found",
+			Details: "The product is not in the shopping cart",
+		})
+	case errors.Is(err, repositories.ErrCartEmpty):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_CART_STATE",
+			Message: "Shopping cart is empty",
+		})
+	case errors.Is(err, repositories.ErrCartCheckedOut):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_CART_STATE",
+			Message: "Shopping cart has already been checked out",
+		})
+	case errors.Is(err, repositories.ErrCartPaid):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_CART_STATE",
+			Message: "Shopping cart has already been paid",
+			Details: "Paid carts cannot be changed; check out the cart instead",
+		})
+	case errors.Is(err, repositories.ErrCartHasPayments):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_CART_STATE",
+			Message: "Shopping cart has payments",
+			Details: "Carts with payment attempts are kept for their payment records",
+		})
+	case errors.Is(err, repositories.ErrConcurrentModification):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "CONFLICT",
+			Message: "Shopping cart is being modified by another request",
+			Details: "Retry the request",
+		})
+	default:
+		respondDatabaseError(c, logger, err, message)
+	}
 }
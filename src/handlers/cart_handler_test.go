@@ -0,0 +1,93 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"store_product/models"
+)
+
+func TestCartItems(t *testing.T) {
+	s := newTestServer(t)
+	s.createProduct(t, 1, 250)
+	s.createProduct(t, 2, 1000)
+	customer := asCustomer(t, 7)
+	cartID := s.createCart(t, customer, 7)
+	path := fmt.Sprintf("/shopping-carts/%s", cartID)
+
+	s.expect(t, http.StatusNoContent, customer, http.MethodPost, path+"/items", `{"product_id": 1, "quantity": 2}`)
+	s.expect(t, http.StatusNoContent, customer, http.MethodPost, path+"/items", `{"product_id": 2, "quantity": 1}`)
+	s.expect(t, http.StatusNoContent, customer, http.MethodPost, path+"/items", `{"product_id": 1, "quantity": 1}`)
+	s.expect(t, http.StatusNoContent, customer, http.MethodPut, path+"/items/2", `{"quantity": 4}`)
+
+	var cart models.CartResponse
+	decode(t, s.expect(t, http.StatusOK, customer, http.MethodGet, path, ""), &cart)
+	if len(cart.Items) != 2 || cart.Items[0].Quantity != 3 || cart.Items[1].Quantity != 4 {
+		t.Fatalf("got items %+v, want product 1 x3 and product 2 x4", cart.Items)
+	}
+	want := models.Money{Amount: 3*250 + 4*1000, Currency: "USD"}
+	if len(cart.Totals) != 1 || cart.Totals[0] != want {
+		t.Fatalf("got totals %+v, want %+v", cart.Totals, want)
+	}
+
+	s.expect(t, http.StatusNoContent, customer, http.MethodDelete, path+"/items/1", "")
+	decode(t, s.expect(t, http.StatusOK, customer, http.MethodGet, path, ""), &cart)
+	if len(cart.Items) != 1 || cart.Items[0].ProductID != 2 {
+		t.Fatalf("got items %+v after removing product 1, want only product 2", cart.Items)
+	}
+}
+
+func TestCartCheckout(t *testing.T) {
+	s := newTestServer(t)
+	s.createProduct(t, 1, 250)
+	customer := asCustomer(t, 7)
+	cartID := s.createCart(t, customer, 7)
+	path := fmt.Sprintf("/shopping-carts/%s", cartID)
+
+	s.expectError(t, http.StatusBadRequest, "INVALID_CART_STATE", customer, http.MethodPost, path+"/checkout", "")
+
+	s.expect(t, http.StatusNoContent, customer, http.MethodPost, path+"/items", `{"product_id": 1, "quantity": 1}`)
+	var resp models.CheckoutResponse
+	decode(t, s.expect(t, http.StatusOK, customer, http.MethodPost, path+"/checkout", ""), &resp)
+	if resp.OrderID == "" {
+		t.Fatal("checkout returned no order ID")
+	}
+
+	// A checked-out cart is frozen
+	s.expectError(t, http.StatusBadRequest, "INVALID_CART_STATE", customer, http.MethodPost, path+"/checkout", "")
+	s.expectError(t, http.StatusBadRequest, "INVALID_CART_STATE", customer, http.MethodPost, path+"/items", `{"product_id": 1, "quantity": 1}`)
+}
+
+func TestCartErrors(t *testing.T) {
+	s := newTestServer(t)
+	s.createProduct(t, 1, 250)
+	owner := asCustomer(t, 7)
+	cartID := s.createCart(t, owner, 7)
+	path := fmt.Sprintf("/shopping-carts/%s", cartID)
+
+	tests := []struct {
+		name   string
+		as     credentials
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"malformed body", owner, http.MethodPost, "/shopping-carts", `{"customer_id": "seven"}`, http.StatusBadRequest, "INVALID_INPUT"},
+		{"zero quantity", owner, http.MethodPost, path + "/items", `{"product_id": 1, "quantity": 0}`, http.StatusBadRequest, "INVALID_INPUT"},
+		{"invalid cart ID", owner, http.MethodGet, "/shopping-carts/abc", "", http.StatusBadRequest, "INVALID_INPUT"},
+		{"cart for another customer", owner, http.MethodPost, "/shopping-carts", `{"customer_id": 8}`, http.StatusForbidden, "FORBIDDEN"},
+		{"token without customer", asCustomer(t, 0), http.MethodGet, path, "", http.StatusForbidden, "FORBIDDEN"},
+		{"cart of another customer", asCustomer(t, 8), http.MethodGet, path, "", http.StatusNotFound, "NOT_FOUND"},
+		{"unknown cart", owner, http.MethodGet, "/shopping-carts/999", "", http.StatusNotFound, "NOT_FOUND"},
+		{"unknown product", owner, http.MethodPost, path + "/items", `{"product_id": 99, "quantity": 1}`, http.StatusNotFound, "NOT_FOUND"},
+		{"item not in cart", owner, http.MethodDelete, path + "/items/1", "", http.StatusNotFound, "NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.expectError(t, tt.status, tt.code, tt.as, tt.method, tt.path, tt.body)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"store_product/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondDatabaseError writes the response for an unexpected repository error
+func respondDatabaseError(c *gin.Context, logger *slog.Logger, err error, message string) {
+	respondInternalError(c, logger, err, "DATABASE_ERROR", message)
+}
+
+// respondInternalError writes the response for an unexpected error, separating
+// requests that ran out of time or were abandoned from genuine failures
+func respondInternalError(c *gin.Context, logger *slog.Logger, err error, code, message string) {
+	ctx := c.Request.Context()
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		logger.WarnContext(ctx, message+": request timed out", "error", err.Error())
+		c.JSON(http.StatusGatewayTimeout, models.ErrorResponse{
+			Error:   "TIMEOUT",
+			Message: message,
+			Details: "The request did not complete within the allowed time",
+		})
+	case errors.Is(err, context.Canceled):
+		logger.WarnContext(ctx, message+": request canceled", "error", err.Error())
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error:   "REQUEST_CANCELED",
+			Message: message,
+			Details: "The request was canceled before it completed",
+		})
+	default:
+		logger.ErrorContext(ctx, message, "error", err.Error())
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"store_product/config"
+	"store_product/handlers"
+	"store_product/models"
+	"store_product/routes"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testAPIKey     = "test-api-key"
+	testSigningKey = "test-signing-key"
+	declinedCard   = "4000000000000002"
+	approvedCard   = "4111111111111111"
+)
+
+// testServer is the full router on the in-memory backend
+type testServer struct {
+	router *gin.Engine
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		Server:  config.ServerConfig{RequestTimeout: 5 * time.Second},
+		CartTTL: time.Hour,
+		Auth: config.AuthConfig{
+			APIKeys:        map[string]string{testAPIKey: "test"},
+			JWTSigningKeys: [][]byte{[]byte(testSigningKey)},
+		},
+		Payments: config.PaymentsConfig{DeclinePrefixes: []string{declinedCard}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	router := gin.New()
+	routes.SetupRoutesWithMemory(router, cfg, logger, handlers.NewHealthHandler(logger))
+	return &testServer{router: router}
+}
+
+// credentials sets the authentication headers of a request
+type credentials func(r *http.Request)
+
+// asAdmin authenticates with the API key, which carries the admin role
+func asAdmin(r *http.Request) {
+	r.Header.Set("X-API-Key", testAPIKey)
+}
+
+// asCustomer authenticates with a bearer token for the customer; customer ID
+// 0 gives a token without a customer identity
+func asCustomer(t *testing.T, customerID int) credentials {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":         "user",
+		"customer_id": customerID,
+		"exp":         time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(testSigningKey))
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+	return func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// do sends a request with an optional JSON body
+func (s *testServer) do(t *testing.T, as credentials, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if as != nil {
+		as(req)
+	}
+
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// expect sends a request and fails the test unless it gets the status
+func (s *testServer) expect(t *testing.T, status int, as credentials, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := s.do(t, as, method, path, body)
+	if rec.Code != status {
+		t.Fatalf("%s %s: got status %d, want %d; body: %s", method, path, rec.Code, status, rec.Body)
+	}
+	return rec
+}
+
+// expectError sends a request and checks the status and error code
+func (s *testServer) expectError(t *testing.T, status int, code string, as credentials, method, path, body string) {
+	t.Helper()
+	var resp models.ErrorResponse
+	decode(t, s.expect(t, status, as, method, path, body), &resp)
+	if resp.Error != code {
+		t.Fatalf("%s %s: got error %q, want %q", method, path, resp.Error, code)
+	}
+}
+
+// createProduct adds a product priced in USD
+func (s *testServer) createProduct(t *testing.T, productID int, cents int64) {
+	t.Helper()
+	weight := 100
+	body, err := json.Marshal(models.Product{
+		ProductID:    productID,
+		SKU:          fmt.Sprintf("SKU-%d", productID),
+		Manufacturer: "Acme",
+		CategoryID:   1,
+		Weight:       &weight,
+		SomeOtherID:  1,
+		Price:        &models.Money{Amount: cents, Currency: "USD"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.expect(t, http.StatusCreated, asAdmin, http.MethodPost, "/products", string(body))
+}
+
+// createCart creates a cart for the customer and returns its ID
+func (s *testServer) createCart(t *testing.T, as credentials, customerID int) models.CartID {
+	t.Helper()
+	var resp models.CreateCartResponse
+	rec := s.expect(t, http.StatusCreated, as, http.MethodPost, "/shopping-carts", fmt.Sprintf(`{"customer_id": %d}`, customerID))
+	decode(t, rec, &resp)
+	return resp.ShoppingCartID
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding %s: %v", rec.Body, err)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	s := newTestServer(t)
+
+	s.expectError(t, http.StatusUnauthorized, "UNAUTHORIZED", nil, http.MethodGet, "/products/1", "")
+	s.expect(t, http.StatusOK, nil, http.MethodGet, "/health/live", "")
+
+	// Unknown paths are not behind authentication
+	s.expect(t, http.StatusNotFound, nil, http.MethodGet, "/no-such-path", "")
+	s.expect(t, http.StatusNotFound, asAdmin, http.MethodGet, "/no-such-path", "")
+}
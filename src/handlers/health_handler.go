@@ -1,24 +1,141 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"store_product/models"
+
 	"github.com/gin-gonic/gin"
 )
 
+// serviceName identifies this service in health responses
+const serviceName = "product-cart-service"
+
+// dependencyCheckTimeout bounds each readiness probe, well inside the
+// load balancer's 5s health check timeout
+const dependencyCheckTimeout = 2 * time.Second
+
+// Dependency is a backing service probed by the readiness check
+type Dependency struct {
+	Name     string
+	Critical bool // a failing critical dependency makes the task not ready
+	Check    func(ctx context.Context) error
+}
+
 // HealthHandler handles health check requests
-type HealthHandler struct{}
+type HealthHandler struct {
+	draining     atomic.Bool
+	dependencies []Dependency
+	logger       *slog.Logger
+}
 
 // NewHealthHandler creates a new health handler
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+func NewHealthHandler(logger *slog.Logger) *HealthHandler {
+	return &HealthHandler{logger: logger}
+}
+
+// AddDependency registers a backing service for the readiness check. It must
+// be called before the server starts handling requests.
+func (h *HealthHandler) AddDependency(dep Dependency) {
+	h.dependencies = append(h.dependencies, dep)
+}
+
+// SetDraining makes the health check fail from now on, so the load balancer
+// stops routing new requests to a task that is shutting down
+func (h *HealthHandler) SetDraining() {
+	h.draining.Store(true)
 }
 
 // Check handles GET /health
 func (h *HealthHandler) Check(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"status":    "healthy",
+	status, code := "healthy", http.StatusOK
+	if h.draining.Load() {
+		status, code = "draining", http.StatusServiceUnavailable
+	}
+
+	c.JSON(code, gin.H{
+		"status":    status,
 		"timestamp": time.Now().Unix(),
-		"service":   "product-cart-service",
+		"service":   serviceName,
 	})
 }
+
+// Live handles GET /health/live. It reports only that the process is
+// serving requests and never touches the backing store.
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "alive",
+		"timestamp": time.Now().Unix(),
+		"service":   serviceName,
+	})
+}
+
+// Ready handles GET /health/ready. It probes every dependency concurrently
+// and returns 503 when a critical one fails or the server is draining.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	results := make(map[string]models.DependencyStatus, len(h.dependencies))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, dep := range h.dependencies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := h.checkDependency(c.Request.Context(), dep)
+			mu.Lock()
+			results[dep.Name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	resp := models.ReadinessResponse{
+		Status:       "ready",
+		Timestamp:    time.Now().Unix(),
+		Service:      serviceName,
+		Dependencies: results,
+	}
+	for _, result := range results {
+		if result.Critical && result.Status != models.DependencyUp {
+			resp.Status = "not_ready"
+		}
+	}
+	if h.draining.Load() {
+		resp.Status = "draining"
+	}
+
+	code := http.StatusOK
+	if resp.Status != "ready" {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, resp)
+}
+
+// checkDependency runs one probe under dependencyCheckTimeout. The endpoint
+// is unauthenticated, so failure details are logged rather than returned.
+func (h *HealthHandler) checkDependency(ctx context.Context, dep Dependency) models.DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, dependencyCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := dep.Check(ctx)
+	result := models.DependencyStatus{
+		Status:    models.DependencyUp,
+		Critical:  dep.Critical,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		h.logger.WarnContext(ctx, "readiness check failed", "dependency", dep.Name, "error", err.Error())
+		result.Status = models.DependencyDown
+		result.Error = "check failed"
+		if errors.Is(err, context.DeadlineExceeded) {
+			result.Error = "timed out"
+		}
+	}
+	return result
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"store_product/models"
+	"store_product/payments"
+	"store_product/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PaymentHandler handles payment requests
+type PaymentHandler struct {
+	processor   payments.PaymentProcessor
+	repo        repositories.PaymentRepositoryInterface
+	cartRepo    repositories.CartRepositoryInterface
+	productRepo repositories.ProductRepositoryInterface
+	logger      *slog.Logger
+}
+
+// NewPaymentHandler creates a new payment handler
+func NewPaymentHandler(processor payments.PaymentProcessor, repo repositories.PaymentRepositoryInterface, cartRepo repositories.CartRepositoryInterface, productRepo repositories.ProductRepositoryInterface, logger *slog.Logger) *PaymentHandler {
+	return &PaymentHandler{processor: processor, repo: repo, cartRepo: cartRepo, productRepo: productRepo, logger: logger}
+}
+
+// Checkout handles POST /payments/checkout
+func (h *PaymentHandler) Checkout(c *gin.Context) {
+	var req models.ProcessPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_INPUT",
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if !payments.ValidCardNumber(req.CreditCardNumber) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_INPUT",
+			Message: "Invalid payment information",
+			Details: "Credit card number must be 13-19 digits",
+		})
+		return
+	}
+
+	cartID := req.ShoppingCartID
+	cart, logger := loadOwnedCart(c, h.cartRepo, h.logger, cartID)
+	if cart == nil {
+		return
+	}
+
+	// A cart is paid at most once; a retried checkout gets the original
+	// transaction instead of a second charge
+	paid, err := h.repo.GetApprovedByCartID(c.Request.Context(), cartID)
+	if err != nil {
+		respondDatabaseError(c, logger, err, "Failed to fetch payment")
+		return
+	}
+	if paid != nil {
+		c.JSON(http.StatusOK, models.ProcessPaymentResponse{
+			Success:       true,
+			TransactionID: paid.TransactionID,
+		})
+		return
+	}
+
+	if cart.IsCheckedOut() {
+		respondCartError(c, logger, repositories.ErrCartCheckedOut, "")
+		return
+	}
+	if len(cart.Items) == 0 {
+		respondCartError(c, logger, repositories.ErrCartEmpty, "")
+		return
+	}
+
+	amount, ok := h.cartTotal(c, logger, cart)
+	if !ok {
+		return
+	}
+
+	result, err := h.processor.Charge(c.Request.Context(), payments.ChargeRequest{
+		CardNumber: req.CreditCardNumber,
+		CartID:     cartID,
+		Amount:     amount,
+	})
+	if err != nil {
+		respondInternalError(c, logger, err, "PAYMENT_ERROR", "Failed to process payment")
+		return
+	}
+
+	payment := &models.Payment{
+		TransactionID: result.TransactionID,
+		CartID:        cartID,
+		CardLastFour:  payments.LastFour(req.CreditCardNumber),
+		Status:        models.PaymentStatusApproved,
+		DeclineReason: result.DeclineReason,
+		CreatedAt:     time.Now(),
+	}
+	if !result.Approved {
+		payment.Status = models.PaymentStatusDeclined
+	}
+
+	// Declined attempts are recorded too so they can be audited. The card has
+	// already been charged, so a client disconnect must not abort the write.
+	err = h.repo.Save(context.WithoutCancel(c.Request.Context()), payment)
+	if result.Approved && isCartStateError(err) {
+		h.refund(c, logger, payment, err)
+		return
+	}
+	if err != nil {
+		respondDatabaseError(c, logger, err, "Failed to record payment")
+		return
+	}
+
+	if !result.Approved {
+		c.JSON(http.StatusPaymentRequired, models.ErrorResponse{
+			Error:   "PAYMENT_DECLINED",
+			Message: "Payment declined",
+			Details: result.DeclineReason,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ProcessPaymentResponse{
+		Success:       true,
+		TransactionID: result.TransactionID,
+	})
+}
+
+// refund gives back an approved charge that could not be recorded because
+// another request paid for, checked out or deleted the cart while the card was
+// being charged. A concurrent payment for the same cart gets that payment's
+// transaction, as a retry would.
+func (h *PaymentHandler) refund(c *gin.Context, logger *slog.Logger, payment *models.Payment, saveErr error) {
+	ctx := context.WithoutCancel(c.Request.Context())
+	logger = logger.With("transaction_id", payment.TransactionID)
+	if err := h.processor.Refund(ctx, payment.TransactionID); err != nil {
+		respondInternalError(c, logger, err, "PAYMENT_ERROR", "Failed to refund duplicate payment")
+		return
+	}
+	logger.WarnContext(ctx, "Refunded a charge for a cart that changed during payment", "error", saveErr.Error())
+
+	if !errors.Is(saveErr, repositories.ErrCartPaid) {
+		respondCartError(c, logger, saveErr, "")
+		return
+	}
+	paid, err := h.repo.GetApprovedByCartID(ctx, payment.CartID)
+	if err != nil {
+		respondDatabaseError(c, logger, err, "Failed to fetch payment")
+		return
+	}
+	if paid == nil {
+		respondCartError(c, logger, saveErr, "")
+		return
+	}
+	c.JSON(http.StatusOK, models.ProcessPaymentResponse{
+		Success:       true,
+		TransactionID: paid.TransactionID,
+	})
+}
+
+// isCartStateError reports whether a payment could not be saved because of
+// the state of its cart, or a concurrent write to it
+func isCartStateError(err error) bool {
+	return errors.Is(err, repositories.ErrCartPaid) ||
+		errors.Is(err, repositories.ErrCartCheckedOut) ||
+		errors.Is(err, repositories.ErrCartNotFound) ||
+		errors.Is(err, repositories.ErrConcurrentModification)
+}
+
+// cartTotal prices the cart with the current product prices. The cart can
+// only be charged when every item has a price and all are in one currency.
+func (h *PaymentHandler) cartTotal(c *gin.Context, logger *slog.Logger, cart *models.ShoppingCart) (models.Money, bool) {
+	prices, err := currentPrices(c.Request.Context(), h.productRepo, cart.Items)
+	if err != nil {
+		respondDatabaseError(c, logger, err, "Failed to fetch product prices")
+		return models.Money{}, false
+	}
+
+	resp := models.NewCartResponse(*cart, prices)
+	for _, line := range resp.Items {
+		if line.UnitPrice == nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "INVALID_CART_STATE",
+				Message: "Shopping cart cannot be priced",
+				Details: fmt.Sprintf("Product %d no longer exists or has no price", line.ProductID),
+			})
+			return models.Money{}, false
+		}
+	}
+	if len(resp.Totals) != 1 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_CART_STATE",
+			Message: "Shopping cart cannot be priced",
+			Details: "All items must be priced in the same currency",
+		})
+		return models.Money{}, false
+	}
+	return resp.Totals[0], true
+}
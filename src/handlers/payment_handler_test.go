@@ -0,0 +1,80 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"store_product/models"
+)
+
+// checkoutBody is a payment request for the cart
+func checkoutBody(card string, cartID models.CartID) string {
+	return fmt.Sprintf(`{"credit_card_number": %q, "shopping_cart_id": %q}`, card, cartID)
+}
+
+func TestPaymentCheckout(t *testing.T) {
+	s := newTestServer(t)
+	s.createProduct(t, 1, 250)
+	customer := asCustomer(t, 7)
+	cartID := s.createCart(t, customer, 7)
+	s.expect(t, http.StatusNoContent, customer, http.MethodPost, fmt.Sprintf("/shopping-carts/%s/items", cartID), `{"product_id": 1, "quantity": 2}`)
+
+	var first models.ProcessPaymentResponse
+	decode(t, s.expect(t, http.StatusOK, customer, http.MethodPost, "/payments/checkout", checkoutBody(approvedCard, cartID)), &first)
+	if !first.Success || first.TransactionID == "" {
+		t.Fatalf("got %+v, want a successful payment", first)
+	}
+
+	// Paying again returns the original transaction rather than a new charge
+	var retry models.ProcessPaymentResponse
+	decode(t, s.expect(t, http.StatusOK, customer, http.MethodPost, "/payments/checkout", checkoutBody(approvedCard, cartID)), &retry)
+	if retry.TransactionID != first.TransactionID {
+		t.Fatalf("retry got transaction %q, want %q", retry.TransactionID, first.TransactionID)
+	}
+
+	// The paid contents are frozen until checkout
+	path := fmt.Sprintf("/shopping-carts/%s", cartID)
+	var cart models.CartResponse
+	decode(t, s.expect(t, http.StatusOK, customer, http.MethodGet, path, ""), &cart)
+	if cart.Status != models.CartStatusPaid {
+		t.Fatalf("got cart status %q after payment, want %q", cart.Status, models.CartStatusPaid)
+	}
+	s.expectError(t, http.StatusBadRequest, "INVALID_CART_STATE", customer, http.MethodPost, path+"/items", `{"product_id": 1, "quantity": 1}`)
+	s.expectError(t, http.StatusBadRequest, "INVALID_CART_STATE", customer, http.MethodPut, path+"/items/1", `{"quantity": 5}`)
+	s.expect(t, http.StatusOK, customer, http.MethodPost, path+"/checkout", "")
+}
+
+func TestPaymentCheckoutErrors(t *testing.T) {
+	s := newTestServer(t)
+	s.createProduct(t, 1, 250)
+	owner := asCustomer(t, 7)
+
+	empty := s.createCart(t, owner, 7)
+	filled := s.createCart(t, owner, 7)
+	s.expect(t, http.StatusNoContent, owner, http.MethodPost, fmt.Sprintf("/shopping-carts/%s/items", filled), `{"product_id": 1, "quantity": 1}`)
+	checkedOut := s.createCart(t, owner, 7)
+	s.expect(t, http.StatusNoContent, owner, http.MethodPost, fmt.Sprintf("/shopping-carts/%s/items", checkedOut), `{"product_id": 1, "quantity": 1}`)
+	s.expect(t, http.StatusOK, owner, http.MethodPost, fmt.Sprintf("/shopping-carts/%s/checkout", checkedOut), "")
+
+	tests := []struct {
+		name   string
+		as     credentials
+		body   string
+		status int
+		code   string
+	}{
+		{"malformed card number", owner, checkoutBody("4111-1111", filled), http.StatusBadRequest, "INVALID_INPUT"},
+		{"empty cart", owner, checkoutBody(approvedCard, empty), http.StatusBadRequest, "INVALID_CART_STATE"},
+		{"checked-out cart", owner, checkoutBody(approvedCard, checkedOut), http.StatusBadRequest, "INVALID_CART_STATE"},
+		{"declined card", owner, checkoutBody(declinedCard, filled), http.StatusPaymentRequired, "PAYMENT_DECLINED"},
+		{"token without customer", asCustomer(t, 0), checkoutBody(approvedCard, filled), http.StatusForbidden, "FORBIDDEN"},
+		{"cart of another customer", asCustomer(t, 8), checkoutBody(approvedCard, filled), http.StatusNotFound, "NOT_FOUND"},
+		{"unknown cart", owner, checkoutBody(approvedCard, "999"), http.StatusNotFound, "NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.expectError(t, tt.status, tt.code, tt.as, http.MethodPost, "/payments/checkout", tt.body)
+		})
+	}
+}
@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"errors"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"store_product/models"
 	"store_product/repositories"
@@ -12,12 +15,84 @@ import (
 
 // ProductHandler handles product-related requests
 type ProductHandler struct {
-	repo *repositories.ProductRepository
+	repo   repositories.ProductRepositoryInterface
+	logger *slog.Logger
 }
 
 // NewProductHandler creates a new product handler
-func NewProductHandler(repo *repositories.ProductRepository) *ProductHandler {
-	return &ProductHandler{repo: repo}
+func NewProductHandler(repo repositories.ProductRepositoryInterface, logger *slog.Logger) *ProductHandler {
+	return &ProductHandler{repo: repo, logger: logger}
+}
+
+// defaultProductPageSize is the number of products returned when no limit is given
+const defaultProductPageSize = 20
+
+// List handles GET /products
+func (h *ProductHandler) List(c *gin.Context) {
+	var query models.ListProductsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_INPUT",
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+	if query.MinWeight != nil && query.MaxWeight != nil && *query.MinWeight > *query.MaxWeight {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_INPUT",
+			Message: "Invalid query parameters",
+			Details: "min_weight must not be greater than max_weight",
+		})
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultProductPageSize
+	}
+
+	opts := repositories.ProductListOptions{
+		Filter: repositories.ProductFilter{
+			Manufacturer: query.Manufacturer,
+			CategoryID:   query.CategoryID,
+			MinWeight:    query.MinWeight,
+			MaxWeight:    query.MaxWeight,
+			SKUPrefix:    query.SKUPrefix,
+		},
+		Limit:  query.Limit,
+		Cursor: query.NextToken,
+	}
+	if query.Sort != "" {
+		field, descending := strings.CutPrefix(query.Sort, "-")
+		opts.SortBy = repositories.ProductSortField(field)
+		opts.Descending = descending
+		if !opts.SortBy.Valid() {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "INVALID_INPUT",
+				Message: "Invalid query parameters",
+				Details: "sort must be one of product_id, sku, manufacturer, category_id or weight, optionally prefixed with -",
+			})
+			return
+		}
+	}
+
+	products, next, err := h.repo.List(c.Request.Context(), opts)
+	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "INVALID_INPUT",
+				Message: "Invalid query parameters",
+				Details: "next_token is not a valid continuation token for this sort order",
+			})
+			return
+		}
+		respondDatabaseError(c, h.logger, err, "Failed to list products")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ListProductsResponse{
+		Products:  products,
+		NextToken: next,
+	})
 }
 
 // GetByID handles GET /products/:productId
@@ -33,8 +108,13 @@ func (h *ProductHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	product, ok := h.repo.GetByID(id)
-	if !ok {
+	product, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		respondDatabaseError(c, h.logger, err, "Failed to fetch product")
+		return
+	}
+
+	if product == nil {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Error:   "NOT_FOUND",
 			Message: "Product not found",
@@ -46,6 +126,26 @@ func (h *ProductHandler) GetByID(c *gin.Context) {
 	c.JSON(http.StatusOK, product)
 }
 
+// GetBySKU handles GET /products/sku/:sku
+func (h *ProductHandler) GetBySKU(c *gin.Context) {
+	product, err := h.repo.GetBySKU(c.Request.Context(), c.Param("sku"))
+	if err != nil {
+		respondDatabaseError(c, h.logger, err, "Failed to fetch product")
+		return
+	}
+
+	if product == nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT_FOUND",
+			Message: "Product not found",
+			Details: "No product has the requested SKU",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
 // Create handles POST /products
 func (h *ProductHandler) Create(c *gin.Context) {
 	var prod models.Product
@@ -58,6 +158,161 @@ func (h *ProductHandler) Create(c *gin.Context) {
 		return
 	}
 
-	h.repo.Save(prod)
+	if err := h.repo.Create(c.Request.Context(), prod); err != nil {
+		respondProductError(c, h.logger, err, "Failed to create product")
+		return
+	}
+
 	c.JSON(http.StatusCreated, prod)
 }
+
+// AddDetails handles POST /products/:productId/details, which writes the full
+// product details over an existing product
+func (h *ProductHandler) AddDetails(c *gin.Context) {
+	id, ok := productIDParam(c)
+	if !ok {
+		return
+	}
+
+	var prod models.Product
+	if err := c.ShouldBindJSON(&prod); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_INPUT",
+			Message: "The provided input data is invalid",
+			Details: err.Error(),
+		})
+		return
+	}
+	if prod.ProductID != id {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_INPUT",
+			Message: "The provided input data is invalid",
+			Details: "product_id in the body must match the product ID in the path",
+		})
+		return
+	}
+
+	if err := h.repo.Update(c.Request.Context(), prod); err != nil {
+		respondProductError(c, h.logger, err, "Failed to save product details")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Replace handles PUT /products/:productId
+func (h *ProductHandler) Replace(c *gin.Context) {
+	id, ok := productIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_INPUT",
+			Message: "The provided input data is invalid",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	prod := models.Product{
+		ProductID:    id,
+		SKU:          req.SKU,
+		Manufacturer: req.Manufacturer,
+		CategoryID:   req.CategoryID,
+		Weight:       req.Weight,
+		SomeOtherID:  req.SomeOtherID,
+		Price:        req.Price,
+	}
+	if err := h.repo.Update(c.Request.Context(), prod); err != nil {
+		respondProductError(c, h.logger, err, "Failed to update product")
+		return
+	}
+
+	c.JSON(http.StatusOK, prod)
+}
+
+// Patch handles PATCH /products/:productId
+func (h *ProductHandler) Patch(c *gin.Context) {
+	id, ok := productIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.PatchProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_INPUT",
+			Message: "The provided input data is invalid",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	prod, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		respondDatabaseError(c, h.logger, err, "Failed to fetch product")
+		return
+	}
+	if prod == nil {
+		respondProductError(c, h.logger, repositories.ErrProductNotFound, "")
+		return
+	}
+
+	req.Apply(prod)
+	if err := h.repo.Update(c.Request.Context(), *prod); err != nil {
+		respondProductError(c, h.logger, err, "Failed to update product")
+		return
+	}
+
+	c.JSON(http.StatusOK, prod)
+}
+
+// Delete handles DELETE /products/:productId
+func (h *ProductHandler) Delete(c *gin.Context) {
+	id, ok := productIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), id); err != nil {
+		respondProductError(c, h.logger, err, "Failed to delete product")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondProductError maps product repository errors to API responses
+func respondProductError(c *gin.Context, logger *slog.Logger, err error, message string) {
+	switch {
+	case errors.Is(err, repositories.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT_FOUND",
+			Message: "Product not found",
+			Details: "The requested product does not exist",
+		})
+	case errors.Is(err, repositories.ErrProductExists):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "CONFLICT",
+			Message: "Product already exists",
+			Details: "A product with this product_id already exists",
+		})
+	case errors.Is(err, repositories.ErrDuplicateSKU):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "CONFLICT",
+			Message: "SKU already in use",
+			Details: "Another product already has this SKU",
+		})
+	case errors.Is(err, repositories.ErrConcurrentModification):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "CONFLICT",
+			Message: "Product is being modified by another request",
+			Details: "Retry the request",
+		})
+	default:
+		respondDatabaseError(c, logger, err, message)
+	}
+}
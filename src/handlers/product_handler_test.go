@@ -0,0 +1,62 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"store_product/models"
+)
+
+func TestProductErrors(t *testing.T) {
+	s := newTestServer(t)
+	s.createProduct(t, 1, 250)
+
+	const valid = `"manufacturer": "Acme", "category_id": 1, "weight": 100, "some_other_id": 1`
+	tests := []struct {
+		name   string
+		as     credentials
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"missing fields", asAdmin, http.MethodPost, "/products", `{"product_id": 2}`, http.StatusBadRequest, "INVALID_INPUT"},
+		{"missing weight", asAdmin, http.MethodPost, "/products", `{"product_id": 2, "sku": "SKU-2", "manufacturer": "Acme", "category_id": 1, "some_other_id": 1}`, http.StatusBadRequest, "INVALID_INPUT"},
+		{"missing weight on replace", asAdmin, http.MethodPut, "/products/1", `{"sku": "SKU-1", "manufacturer": "Acme", "category_id": 1, "some_other_id": 1}`, http.StatusBadRequest, "INVALID_INPUT"},
+		{"negative weight", asAdmin, http.MethodPost, "/products", `{"product_id": 2, "sku": "SKU-2", "manufacturer": "Acme", "category_id": 1, "weight": -1, "some_other_id": 1}`, http.StatusBadRequest, "INVALID_INPUT"},
+		{"invalid currency", asAdmin, http.MethodPost, "/products", `{"product_id": 2, "sku": "SKU-2", ` + valid + `, "price": {"amount": 1, "currency": "usd"}}`, http.StatusBadRequest, "INVALID_INPUT"},
+		{"existing product", asAdmin, http.MethodPost, "/products", `{"product_id": 1, "sku": "SKU-2", ` + valid + `}`, http.StatusConflict, "CONFLICT"},
+		{"duplicate SKU", asAdmin, http.MethodPost, "/products", `{"product_id": 2, "sku": "SKU-1", ` + valid + `}`, http.StatusConflict, "CONFLICT"},
+		{"unknown product", asAdmin, http.MethodGet, "/products/99", "", http.StatusNotFound, "NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.expectError(t, tt.status, tt.code, tt.as, tt.method, tt.path, tt.body)
+		})
+	}
+}
+
+func TestProductWithoutPrice(t *testing.T) {
+	s := newTestServer(t)
+
+	// The API spec's product schema has no price, so it must be optional
+	body := `{"product_id": 1, "sku": "SKU-1", "manufacturer": "Acme", "category_id": 1, "weight": 100, "some_other_id": 1}`
+	s.expect(t, http.StatusCreated, asAdmin, http.MethodPost, "/products", body)
+	s.expect(t, http.StatusNoContent, asAdmin, http.MethodPost, "/products/1/details", body)
+}
+
+func TestProductZeroWeight(t *testing.T) {
+	s := newTestServer(t)
+
+	// Weight is required, but 0 is a valid weight
+	body := `{"product_id": 1, "sku": "SKU-1", "manufacturer": "Acme", "category_id": 1, "weight": 0, "some_other_id": 1}`
+	s.expect(t, http.StatusCreated, asAdmin, http.MethodPost, "/products", body)
+	s.expect(t, http.StatusOK, asAdmin, http.MethodPut, "/products/1", `{"sku": "SKU-1", "manufacturer": "Acme", "category_id": 1, "weight": 0, "some_other_id": 1}`)
+
+	var prod models.Product
+	decode(t, s.expect(t, http.StatusOK, asAdmin, http.MethodGet, "/products/1", ""), &prod)
+	if prod.Weight == nil || *prod.Weight != 0 {
+		t.Fatalf("got weight %v, want 0", prod.Weight)
+	}
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"store_product/models"
+	"store_product/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WarehouseHandler handles warehouse inventory requests
+type WarehouseHandler struct {
+	repo        repositories.InventoryRepositoryInterface
+	productRepo repositories.ProductRepositoryInterface
+	logger      *slog.Logger
+}
+
+// NewWarehouseHandler creates a new warehouse handler
+func NewWarehouseHandler(repo repositories.InventoryRepositoryInterface, productRepo repositories.ProductRepositoryInterface, logger *slog.Logger) *WarehouseHandler {
+	return &WarehouseHandler{repo: repo, productRepo: productRepo, logger: logger}
+}
+
+// GetInventory handles GET /warehouse/inventory/:productId
+func (h *WarehouseHandler) GetInventory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("productId"))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_INPUT",
+			Message: "The provided input data is invalid",
+			Details: "Product ID must be a positive integer",
+		})
+		return
+	}
+
+	if !h.productExists(c, id) {
+		return
+	}
+
+	inv, err := h.repo.Get(c.Request.Context(), id)
+	if err != nil {
+		respondDatabaseError(c, h.logger, err, "Failed to fetch inventory")
+		return
+	}
+	if inv == nil {
+		// Known product that has never been stocked
+		inv = &models.Inventory{ProductID: id}
+	}
+
+	c.JSON(http.StatusOK, inv)
+}
+
+// Restock handles POST /warehouse/restock
+func (h *WarehouseHandler) Restock(c *gin.Context) {
+	req, ok := h.bindRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Restock(c.Request.Context(), req.ProductID, req.Quantity); err != nil {
+		respondDatabaseError(c, h.logger, err, "Failed to restock inventory")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Reserve handles POST /warehouse/reserve
+func (h *WarehouseHandler) Reserve(c *gin.Context) {
+	req, ok := h.bindRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Reserve(c.Request.Context(), req.ProductID, req.Quantity); err != nil {
+		if errors.Is(err, repositories.ErrInsufficientInventory) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "INSUFFICIENT_INVENTORY",
+				Message: "Insufficient inventory",
+				Details: "Not enough available stock to reserve the requested quantity",
+			})
+			return
+		}
+		respondDatabaseError(c, h.logger, err, "Failed to reserve inventory")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Ship handles POST /warehouse/ship
+func (h *WarehouseHandler) Ship(c *gin.Context) {
+	req, ok := h.bindRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Ship(c.Request.Context(), req.ProductID, req.Quantity); err != nil {
+		if errors.Is(err, repositories.ErrInsufficientReserved) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "INSUFFICIENT_RESERVED_INVENTORY",
+				Message: "Insufficient reserved inventory",
+				Details: "Not enough reserved stock to ship the requested quantity",
+			})
+			return
+		}
+		respondDatabaseError(c, h.logger, err, "Failed to ship inventory")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// bindRequest parses the request body and verifies the product exists,
+// writing the error response itself when it returns false
+func (h *WarehouseHandler) bindRequest(c *gin.Context) (models.InventoryRequest, bool) {
+	var req models.InventoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_INPUT",
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+		return req, false
+	}
+	return req, h.productExists(c, req.ProductID)
+}
+
+// productExists writes an error response and returns false when the product
+// is unknown or cannot be looked up
+func (h *WarehouseHandler) productExists(c *gin.Context, productID int) bool {
+	exists, err := h.productRepo.Exists(c.Request.Context(), productID)
+	if err != nil {
+		respondDatabaseError(c, h.logger, err, "Failed to verify product")
+		return false
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT_FOUND",
+			Message: "Product not found",
+			Details: "The requested product does not exist",
+		})
+		return false
+	}
+	return true
+}
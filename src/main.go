@@ -1,58 +1,151 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"store_product/config"
+	"store_product/handlers"
+	"store_product/logging"
+	"store_product/middleware"
+	"store_product/migrations"
 	"store_product/routes"
+	"store_product/tracing"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
+// serviceName names the service in traces unless OTEL_SERVICE_NAME is set
+const serviceName = "product-cart-service"
+
 func main() {
-	// Load environment variables from .env file
-	// if err := godotenv.Load(); err != nil {
-	// 	log.Printf("Warning: Could not load .env file: %v", err)
-	// }
+	// Read and validate the whole configuration before doing anything else
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+
+	// Log JSON records; the standard log package is routed through the same logger
+	logger := logging.New(os.Stdout, cfg.LogLevel)
+	slog.SetDefault(logger)
+
+	if len(args) > 0 {
+		if args[0] != "migrate" {
+			fmt.Fprintf(os.Stderr, "unknown command %q\n%s\n", args[0], migrateUsage)
+			os.Exit(2)
+		}
+		runMigrate(cfg, args[1:])
+		return
+	}
+
+	// Export spans before any traced client is created
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.TracesExporter, serviceName)
+	if err != nil {
+		fatal("failed to initialize tracing", err)
+	}
+
+	logger.Info("starting", "database_type", cfg.DatabaseType)
+	if !cfg.Auth.Configured() {
+		logger.Warn("neither API_KEYS nor JWT_SIGNING_KEYS is set; all authenticated requests will be rejected")
+	}
 
-	// Get database type from environment
-	dbType := config.GetDatabaseType()
-	log.Printf("Database type: %s", dbType)
+	// Gin's debug output is plain text; keep it out of the JSON log unless asked for
+	if os.Getenv(gin.EnvGinMode) == "" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// Initialize Gin router. The request span comes first so the access log
+	// carries its trace ID, then request IDs so every later log carries one.
+	// Probes and scrapes are not traced.
+	router := gin.New()
+	router.Use(
+		otelgin.Middleware(serviceName, otelgin.WithFilter(tracedRequest)),
+		middleware.RequestID(),
+		middleware.AccessLog(logger),
+		middleware.Recovery(logger),
+	)
+	healthHandler := handlers.NewHealthHandler(logger)
 
-	// Initialize Gin router
-	router := gin.Default()
+	// closeStore releases the storage backend once the server has drained
+	closeStore := func() {}
 
 	// Setup routes based on database type
-	if dbType == "dynamodb" {
+	switch cfg.DatabaseType {
+	case "memory":
+		logger.Warn("using in-memory storage; data will be lost on restart")
+		routes.SetupRoutesWithMemory(router, cfg, logger, healthHandler)
+	case "dynamodb":
 		// Initialize DynamoDB
-		dynamoClient, tableName, err := config.InitDynamoDB()
+		dynamoClient, closeConns, err := config.InitDynamoDB(cfg.DynamoDB)
 		if err != nil {
-			log.Fatal("Failed to initialize DynamoDB:", err)
+			fatal("failed to initialize DynamoDB", err)
 		}
-		log.Printf("DynamoDB initialized successfully with table: %s", tableName)
+		closeStore = closeConns
+		logger.Info("DynamoDB initialized", "carts_table", cfg.DynamoDB.Tables.Carts)
 
-		routes.SetupRoutesWithDynamoDB(router, dynamoClient, tableName)
-	} else {
+		routes.SetupRoutesWithDynamoDB(router, cfg, logger, healthHandler, dynamoClient)
+	default:
 		// Initialize MySQL (default)
-		db, err := config.InitDB()
+		db, err := config.InitDB(cfg.MySQL)
 		if err != nil {
-			log.Fatal("Failed to connect to MySQL database:", err)
+			fatal("failed to connect to MySQL database", err)
+		}
+		closeStore = func() {
+			if err := db.Close(); err != nil {
+				logger.Error("failed to close MySQL connection pool", "error", err.Error())
+			}
 		}
-		defer db.Close()
-		log.Println("MySQL database connection established and successfully initialized.")
 
-		routes.SetupRoutes(router, db)
+		if cfg.MigrateOnStart {
+			if err := migrations.Up(context.Background(), db); err != nil {
+				fatal("failed to migrate MySQL database", err)
+			}
+		}
+		logger.Info("MySQL database connection established")
+
+		routes.SetupRoutes(router, cfg, logger, healthHandler, db)
 	}
 
-	// Get port from environment or default to 8080
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// A failed server still releases the backend and flushes its spans
+	serveErr := serve(cfg.Server, router, healthHandler, logger)
+	if serveErr != nil {
+		logger.Error("server failed", "error", serveErr.Error())
 	}
+	closeStore()
 
-	log.Printf("Server starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	// Flush the spans of the last requests
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("failed to flush traces", "error", err.Error())
 	}
+	cancel()
+
+	if serveErr != nil {
+		os.Exit(1)
+	}
+	logger.Info("server stopped")
+}
+
+// tracedRequest reports whether a request gets a span: health checks and
+// metrics scrapes arrive every few seconds and would drown out real traffic
+func tracedRequest(r *http.Request) bool {
+	return !strings.HasPrefix(r.URL.Path, "/health") && r.URL.Path != "/metrics"
+}
+
+// fatal logs err through the default structured logger and exits, like log.Fatal
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err.Error())
+	os.Exit(1)
 }
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var dynamoDBCapacity = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "dynamodb_consumed_capacity_units_total",
+	Help:      "Capacity units consumed by DynamoDB operations, by table and operation.",
+}, []string{"table", "operation"})
+
+// RecordConsumedCapacity is a DynamoDB client option that asks every
+// operation supporting it to return its consumed capacity and records that
+// capacity. Inputs that already set ReturnConsumedCapacity are left alone.
+func RecordConsumedCapacity(o *dynamodb.Options) {
+	o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("ConsumedCapacityMetrics",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				requestConsumedCapacity(in.Parameters)
+				out, metadata, err := next.HandleInitialize(ctx, in)
+				if err == nil {
+					recordConsumedCapacity(awsmiddleware.GetOperationName(ctx), out.Result)
+				}
+				return out, metadata, err
+			}), middleware.After)
+	})
+}
+
+// requestConsumedCapacity sets ReturnConsumedCapacity to TOTAL on inputs that
+// have the field. The inputs of the operations differ in type, hence reflection.
+func requestConsumedCapacity(params interface{}) {
+	v := reflect.ValueOf(params)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	field := v.Elem().FieldByName("ReturnConsumedCapacity")
+	if field.IsValid() && field.CanSet() && field.Kind() == reflect.String && field.String() == "" {
+		field.SetString(string(types.ReturnConsumedCapacityTotal))
+	}
+}
+
+// recordConsumedCapacity adds the capacity reported in an operation's output,
+// which is a single value or one per table depending on the operation
+func recordConsumedCapacity(operation string, result interface{}) {
+	v := reflect.ValueOf(result)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	field := v.Elem().FieldByName("ConsumedCapacity")
+	if !field.IsValid() {
+		return
+	}
+
+	switch consumed := field.Interface().(type) {
+	case *types.ConsumedCapacity:
+		if consumed != nil {
+			addCapacity(operation, *consumed)
+		}
+	case []types.ConsumedCapacity:
+		for _, c := range consumed {
+			addCapacity(operation, c)
+		}
+	}
+}
+
+func addCapacity(operation string, c types.ConsumedCapacity) {
+	dynamoDBCapacity.WithLabelValues(aws.ToString(c.TableName), operation).Add(aws.ToFloat64(c.CapacityUnits))
+}
@@ -0,0 +1,105 @@
+// Package metrics exposes Prometheus metrics for comparing the storage
+// backends under load: HTTP request rates and latencies by route, repository
+// operation latencies and errors by backend, MySQL connection pool statistics
+// and DynamoDB consumed capacity.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"store_product/repositories"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric name
+const namespace = "store_product"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by route, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time to handle HTTP requests, by route, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	repositoryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "repository_operation_duration_seconds",
+		Help:      "Time spent in repository operations, by backend, repository and operation.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"backend", "repository", "operation"})
+
+	repositoryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "repository_operation_errors_total",
+		Help:      "Repository operations that failed, by backend, repository, operation and kind of failure.",
+	}, []string{"backend", "repository", "operation", "kind"})
+)
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveHTTPRequest records a handled request. route is the matched route
+// pattern, not the raw path, to keep label cardinality bounded.
+func ObserveHTTPRequest(route, method string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(route, method, code).Inc()
+	httpDuration.WithLabelValues(route, method, code).Observe(duration.Seconds())
+}
+
+// RegisterDBStats exports the connection pool statistics of db
+func RegisterDBStats(db *sql.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, "mysql"))
+}
+
+// RepositoryObserver returns an observer recording repository operations
+// under the given backend label ("mysql", "dynamodb" or "memory")
+func RepositoryObserver(backend string) repositories.OperationObserver {
+	return repositoryObserver(backend)
+}
+
+// repositoryObserver records repository operations under its backend label
+type repositoryObserver string
+
+// ObserveOperation records the latency of every operation and counts the
+// ones that failed. Expected outcomes such as a missing cart are not failures.
+func (b repositoryObserver) ObserveOperation(repository, operation string, duration time.Duration, err error) {
+	repositoryDuration.WithLabelValues(string(b), repository, operation).Observe(duration.Seconds())
+	if kind := errorKind(err); kind != "" {
+		repositoryErrors.WithLabelValues(string(b), repository, operation, kind).Inc()
+	}
+}
+
+// errorKind classifies a repository error, returning "" for success and
+// expected outcomes
+func errorKind(err error) string {
+	switch {
+	case err == nil, repositories.IsExpectedError(err):
+		return ""
+	case errors.Is(err, repositories.ErrConcurrentModification):
+		return "conflict"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "error"
+	}
+}
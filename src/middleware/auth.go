@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"store_product/auth"
+	"store_product/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authenticate rejects requests without a valid API key or bearer token and
+// stores the authenticated principal in the request context for the handlers
+func Authenticate(authenticator *auth.Authenticator, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := authenticator.Authenticate(c.Request)
+		if err != nil {
+			if !errors.Is(err, auth.ErrMissingCredentials) {
+				logger.WarnContext(c.Request.Context(), "authentication failed",
+					"method", c.Request.Method,
+					"path", c.Request.URL.Path,
+					"error", err.Error(),
+				)
+			}
+			c.Header("WWW-Authenticate", `Bearer realm="store_product"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "UNAUTHORIZED",
+				Message: "Authentication required",
+				Details: "Provide a valid X-API-Key header or Authorization: Bearer token",
+			})
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.NewContext(c.Request.Context(), principal))
+		c.Next()
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"time"
+
+	"store_product/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records the count and latency of every request, labelled with the
+// matched route pattern; requests that match no route share one label
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveHTTPRequest(route, c.Request.Method, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"regexp"
+
+	"store_product/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID limits accepted IDs to short tokens that are safe to log
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID adopts the caller's X-Request-ID, or generates one when it is
+// missing or malformed, echoes it in the response and stores it in the
+// request context for logging
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.New().String()
+		}
+
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
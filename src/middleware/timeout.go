@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestTimeout bounds the request context with the given timeout.
+// Handlers pass c.Request.Context() to the repositories, so database calls are
+// canceled when the deadline passes or the client disconnects.
+func RequestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"store_product/config"
+	"store_product/migrations"
+)
+
+const migrateUsage = `usage: store_product [flags] migrate <command>
+
+commands:
+  up        apply all pending migrations (default)
+  down [N]  revert the last N applied migrations (default 1)
+  status    list migrations and when they were applied`
+
+// runMigrate implements the "migrate" subcommand against the configured MySQL database
+func runMigrate(cfg *config.Config, args []string) {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	steps := 1
+	switch command {
+	case "up", "status":
+		if len(args) > 1 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			os.Exit(2)
+		}
+	case "down":
+		if len(args) > 2 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			os.Exit(2)
+		}
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				fmt.Fprintln(os.Stderr, migrateUsage)
+				os.Exit(2)
+			}
+			steps = n
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	if cfg.DatabaseType != "mysql" {
+		fatal("migrations only apply to MySQL", fmt.Errorf("DATABASE_TYPE is %q", cfg.DatabaseType))
+	}
+
+	db, err := config.InitDB(cfg.MySQL)
+	if err != nil {
+		fatal("failed to connect to MySQL database", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		err = migrations.Up(ctx, db)
+	case "down":
+		err = migrations.Down(ctx, db, steps)
+	case "status":
+		var statuses []migrations.Status
+		statuses, err = migrations.List(ctx, db)
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, applied)
+		}
+	}
+	if err != nil {
+		fatal("migration failed", err)
+	}
+}
@@ -0,0 +1,277 @@
+// Package migrations applies the versioned MySQL schema embedded from sql/.
+//
+// Each migration is a pair of files named NNNN_name.up.sql and
+// NNNN_name.down.sql. Applied versions are recorded in schema_migrations,
+// and a MySQL named lock keeps concurrently starting tasks from migrating at
+// the same time. MySQL commits DDL implicitly, so a migration that fails half
+// way is not rolled back; it stays unrecorded and must be fixed by hand.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockName is the MySQL named lock held while migrating
+const lockName = "store_product.schema_migrations"
+
+// lockTimeout bounds how long to wait for another task to finish migrating
+const lockTimeout = 60 * time.Second
+
+// ErrLockTimeout is returned when another process holds the migration lock too long
+var ErrLockTimeout = errors.New("timed out waiting for the migration lock")
+
+// Migration is one numbered schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status reports whether a migration has been applied
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load returns the embedded migrations ordered by version
+func Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file name %q", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		body, err := fs.ReadFile(files, "sql/"+entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies every pending migration in version order
+func Up(ctx context.Context, db *sql.DB) error {
+	migrations, err := Load()
+	if err != nil {
+		return err
+	}
+
+	return withLock(ctx, db, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			slog.Info("applying migration", "version", m.Version, "name", m.Name)
+			if err := execScript(ctx, conn, m.Up); err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+			}
+			_, err := conn.ExecContext(ctx,
+				"INSERT INTO schema_migrations (version, name) VALUES (?, ?)",
+				m.Version, m.Name,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to record migration %d_%s: %w", m.Version, m.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Down reverts the most recently applied migrations, newest first
+func Down(ctx context.Context, db *sql.DB, steps int) error {
+	migrations, err := Load()
+	if err != nil {
+		return err
+	}
+
+	return withLock(ctx, db, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+			m := migrations[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			slog.Info("reverting migration", "version", m.Version, "name", m.Name)
+			if err := execScript(ctx, conn, m.Down); err != nil {
+				return fmt.Errorf("revert of migration %d_%s failed: %w", m.Version, m.Name, err)
+			}
+			if _, err := conn.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", m.Version); err != nil {
+				return fmt.Errorf("failed to unrecord migration %d_%s: %w", m.Version, m.Name, err)
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+// List returns every known migration with the time it was applied, if it was
+func List(ctx context.Context, db *sql.DB) ([]Status, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	for _, m := range migrations {
+		status := Status{Migration: m}
+		if at, ok := applied[m.Version]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// withLock runs fn on a dedicated connection holding the migration lock.
+// MySQL named locks belong to a session, so everything must use that connection.
+func withLock(ctx context.Context, db *sql.DB, fn func(conn *sql.Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	var acquired sql.NullInt64
+	err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, int(lockTimeout.Seconds())).Scan(&acquired)
+	if err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return ErrLockTimeout
+	}
+	defer func() {
+		// Release even if ctx was canceled; the lock would otherwise live until the connection closes
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), "SELECT RELEASE_LOCK(?)", lockName); err != nil {
+			slog.Error("failed to release migration lock", "error", err.Error())
+		}
+	}()
+
+	return fn(conn)
+}
+
+// appliedVersions creates the tracking table if needed and returns the
+// applied versions with their timestamps
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	_, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		) ENGINE=InnoDB
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		applied[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schema_migrations rows: %w", err)
+	}
+	return applied, nil
+}
+
+// execScript runs each statement of a migration file in turn. Statements end
+// with a semicolon at the end of a line; lines starting with -- are comments.
+func execScript(ctx context.Context, conn *sql.Conn, script string) error {
+	var stmt strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		stmt.WriteString(line)
+		stmt.WriteString("\n")
+		if !strings.HasSuffix(trimmed, ";") {
+			continue
+		}
+
+		query := strings.TrimSuffix(strings.TrimSpace(stmt.String()), ";")
+		stmt.Reset()
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+	if rest := strings.TrimSpace(stmt.String()); rest != "" {
+		return fmt.Errorf("statement is missing its terminating semicolon: %.40q", rest)
+	}
+	return nil
+}
@@ -0,0 +1,3 @@
+-- Carts become read-only once checked out. Existing carts stay active.
+ALTER TABLE shopping_carts
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active' AFTER customer_id;
@@ -0,0 +1,19 @@
+-- Orders created by checking out a cart, with the items bought
+CREATE TABLE orders (
+    order_id INT AUTO_INCREMENT PRIMARY KEY,
+    cart_id INT NOT NULL,
+    customer_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (cart_id) REFERENCES shopping_carts(cart_id),
+    UNIQUE KEY unique_cart_order (cart_id),
+    INDEX idx_order_customer_id (customer_id)
+) ENGINE=InnoDB;
+
+CREATE TABLE order_items (
+    order_id INT NOT NULL,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL,
+    PRIMARY KEY (order_id, product_id),
+    FOREIGN KEY (order_id) REFERENCES orders(order_id) ON DELETE CASCADE,
+    CHECK (quantity > 0)
+) ENGINE=InnoDB;
@@ -0,0 +1,9 @@
+-- Warehouse stock per product; reserved units are held for checkouts
+CREATE TABLE inventory (
+    product_id INT PRIMARY KEY,
+    on_hand INT NOT NULL DEFAULT 0,
+    reserved INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CHECK (reserved >= 0),
+    CHECK (on_hand >= reserved)
+) ENGINE=InnoDB;
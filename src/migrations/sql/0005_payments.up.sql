@@ -0,0 +1,11 @@
+-- Card payments made for carts
+CREATE TABLE payments (
+    transaction_id VARCHAR(36) PRIMARY KEY,
+    cart_id INT NOT NULL,
+    card_last_four CHAR(4) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    decline_reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (cart_id) REFERENCES shopping_carts(cart_id),
+    INDEX idx_payment_cart_id (cart_id)
+) ENGINE=InnoDB;
@@ -0,0 +1,12 @@
+-- Product catalog, previously kept only in memory
+CREATE TABLE products (
+    product_id INT PRIMARY KEY,
+    sku VARCHAR(100) NOT NULL,
+    manufacturer VARCHAR(200) NOT NULL,
+    category_id INT NOT NULL,
+    weight INT NOT NULL,
+    some_other_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_category_id (category_id)
+) ENGINE=InnoDB;
@@ -0,0 +1,5 @@
+DROP INDEX idx_products_weight ON products;
+
+DROP INDEX idx_products_sku ON products;
+
+DROP INDEX idx_products_manufacturer ON products;
@@ -0,0 +1,6 @@
+-- Support filtering and sorting the catalog in GET /products
+CREATE INDEX idx_products_manufacturer ON products (manufacturer, product_id);
+
+CREATE INDEX idx_products_sku ON products (sku, product_id);
+
+CREATE INDEX idx_products_weight ON products (weight, product_id);
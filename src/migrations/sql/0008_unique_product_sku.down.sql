@@ -0,0 +1,3 @@
+CREATE INDEX idx_products_sku ON products (sku, product_id);
+
+DROP INDEX uq_products_sku ON products;
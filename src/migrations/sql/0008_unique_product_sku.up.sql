@@ -0,0 +1,5 @@
+-- SKUs identify products uniquely. Fails if existing products share a SKU;
+-- resolve the duplicates first.
+CREATE UNIQUE INDEX uq_products_sku ON products (sku);
+
+DROP INDEX idx_products_sku ON products;
@@ -0,0 +1,7 @@
+ALTER TABLE cart_items
+    DROP COLUMN price_currency,
+    DROP COLUMN price_amount;
+
+ALTER TABLE products
+    DROP COLUMN price_currency,
+    DROP COLUMN price_amount;
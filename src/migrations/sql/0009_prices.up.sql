@@ -0,0 +1,9 @@
+-- Product prices in minor units, and the price snapshot taken when an item
+-- is added to a cart. Prices are optional; existing products have none.
+ALTER TABLE products
+    ADD COLUMN price_amount BIGINT NULL,
+    ADD COLUMN price_currency CHAR(3) NULL;
+
+ALTER TABLE cart_items
+    ADD COLUMN price_amount BIGINT NULL,
+    ADD COLUMN price_currency CHAR(3) NULL;
@@ -0,0 +1,5 @@
+UPDATE shopping_carts SET status = 'active' WHERE status = 'paid';
+
+ALTER TABLE payments
+    DROP INDEX uq_payments_approved_cart,
+    DROP COLUMN approved_cart_id;
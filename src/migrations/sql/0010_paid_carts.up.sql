@@ -0,0 +1,11 @@
+-- A cart has at most one approved payment, which freezes it in the 'paid'
+-- status until checkout. MySQL has no partial indexes, so the unique key is
+-- on a generated column that is NULL for declined payments. Fails if a cart
+-- already has two approved payments; resolve those first.
+ALTER TABLE payments
+    ADD COLUMN approved_cart_id INT AS (CASE WHEN status = 'approved' THEN cart_id END) STORED,
+    ADD UNIQUE KEY uq_payments_approved_cart (approved_cart_id);
+
+UPDATE shopping_carts SET status = 'paid'
+    WHERE status = 'active'
+    AND cart_id IN (SELECT cart_id FROM payments WHERE status = 'approved');
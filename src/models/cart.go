@@ -2,14 +2,50 @@ package models
 
 import "time"
 
+// Shopping cart statuses
+const (
+	CartStatusActive     = "active"
+	CartStatusPaid       = "paid" // items are frozen; the cart can still be checked out
+	CartStatusCheckedOut = "checked_out"
+)
+
 // ShoppingCart represents a customer's shopping cart
 type ShoppingCart struct {
-	CartID     interface{} `json:"cart_id" dynamodbav:"cart_id"` // Can be int (MySQL) or string (DynamoDB UUID)
-	CustomerID int         `json:"customer_id" dynamodbav:"customer_id"`
-	CreatedAt  time.Time   `json:"created_at" dynamodbav:"created_at"`
-	UpdatedAt  time.Time   `json:"updated_at" dynamodbav:"updated_at"`
-	Items      []CartItem  `json:"items" dynamodbav:"cart_items"`
-	TTL        *int64      `json:"ttl,omitempty" dynamodbav:"ttl,omitempty"` // TTL for DynamoDB (Unix timestamp)
+	CartID     CartID     `json:"cart_id" dynamodbav:"cart_id"`
+	CustomerID int        `json:"customer_id" dynamodbav:"customer_id"`
+	Status     string     `json:"status" dynamodbav:"status"`
+	CreatedAt  time.Time  `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" dynamodbav:"updated_at"`
+	Items      []CartItem `json:"items" dynamodbav:"cart_items"`
+	TTL        *int64     `json:"ttl,omitempty" dynamodbav:"ttl,omitempty"` // TTL for DynamoDB (Unix timestamp)
+	Version    int        `json:"-" dynamodbav:"version,omitempty"`         // Optimistic concurrency version for DynamoDB
+	// PaymentAttempts counts the payments recorded for the cart, approved or
+	// not, so carts with payments are not deleted. MySQL reads the payments
+	// table instead.
+	PaymentAttempts int `json:"-" dynamodbav:"payment_attempts,omitempty"`
+}
+
+// IsCheckedOut reports whether the cart has already been turned into an order
+func (c *ShoppingCart) IsCheckedOut() bool {
+	return c.Status == CartStatusCheckedOut
+}
+
+// IsPaid reports whether the cart has an approved payment and is waiting to
+// be checked out
+func (c *ShoppingCart) IsPaid() bool {
+	return c.Status == CartStatusPaid
+}
+
+// NextItemID returns the ID for a new item: one past the highest item ID in
+// the cart, so removing an item can never make a later add reuse a live ID
+func (c *ShoppingCart) NextItemID() int {
+	next := 1
+	for _, item := range c.Items {
+		if item.ItemID >= next {
+			next = item.ItemID + 1
+		}
+	}
+	return next
 }
 
 // CartItem represents an item in a shopping cart
@@ -19,6 +55,54 @@ type CartItem struct {
 	Quantity  int       `json:"quantity" dynamodbav:"quantity"`
 	AddedAt   time.Time `json:"added_at" dynamodbav:"added_at"`
 	UpdatedAt time.Time `json:"updated_at" dynamodbav:"updated_at"`
+	// PriceAtAdd is the product price when the item was first added; nil for
+	// items added before prices existed
+	PriceAtAdd *Money `json:"price_at_add,omitempty" dynamodbav:"price_at_add,omitempty"`
+}
+
+// CartLine is a cart item priced at the product's current price
+type CartLine struct {
+	CartItem
+	UnitPrice *Money `json:"unit_price,omitempty"` // nil if the product no longer exists or has no price
+	Subtotal  *Money `json:"subtotal,omitempty"`
+}
+
+// CartResponse is a shopping cart with its items priced. Items shadows the
+// embedded cart's unpriced items in the JSON output.
+type CartResponse struct {
+	ShoppingCart
+	Items  []CartLine `json:"items"`
+	Totals []Money    `json:"totals"` // one total per currency in the cart
+}
+
+// NewCartResponse prices the cart's items with the given current product
+// prices. Items whose product has no price are left out of the totals.
+func NewCartResponse(cart ShoppingCart, prices map[int]Money) CartResponse {
+	resp := CartResponse{
+		ShoppingCart: cart,
+		Items:        make([]CartLine, 0, len(cart.Items)),
+		Totals:       []Money{},
+	}
+
+	totals := make(map[string]int)
+	for _, item := range cart.Items {
+		line := CartLine{CartItem: item}
+		if price, ok := prices[item.ProductID]; ok {
+			subtotal := price.Times(item.Quantity)
+			line.UnitPrice = &price
+			line.Subtotal = &subtotal
+
+			i, ok := totals[price.Currency]
+			if !ok {
+				i = len(resp.Totals)
+				totals[price.Currency] = i
+				resp.Totals = append(resp.Totals, Money{Currency: price.Currency})
+			}
+			resp.Totals[i].Amount += subtotal.Amount
+		}
+		resp.Items = append(resp.Items, line)
+	}
+	return resp
 }
 
 // CreateCartRequest represents the request body for creating a cart
@@ -28,9 +112,7 @@ type CreateCartRequest struct {
 
 // CreateCartResponse represents the response after creating a cart
 type CreateCartResponse struct {
-	// Use interface{} so the response can hold either an int (MySQL auto-inc id)
-	// or a string (DynamoDB UUID). JSON marshalling will preserve the underlying type.
-	ShoppingCartID interface{} `json:"shopping_cart_id"`
+	ShoppingCartID CartID `json:"shopping_cart_id"`
 }
 
 // AddItemRequest represents the request body for adding items to cart
@@ -39,6 +121,24 @@ type AddItemRequest struct {
 	Quantity  int `json:"quantity" binding:"required,min=1"`
 }
 
+// UpdateItemRequest represents the request body for setting an item's quantity
+type UpdateItemRequest struct {
+	Quantity int `json:"quantity" binding:"required,min=1"`
+}
+
+// ListCartsQuery represents the query parameters for listing a customer's carts
+type ListCartsQuery struct {
+	Limit        int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	NextToken    string `form:"next_token"`
+	IncludeItems bool   `form:"include_items"`
+}
+
+// ListCartsResponse represents a page of a customer's carts
+type ListCartsResponse struct {
+	ShoppingCarts []ShoppingCart `json:"shopping_carts"`
+	NextToken     string         `json:"next_token,omitempty"`
+}
+
 // ErrorResponse represents an API error response
 type ErrorResponse struct {
 	Error   string `json:"error"`
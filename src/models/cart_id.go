@@ -0,0 +1,37 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// CartID identifies a shopping cart. It is always serialized as a JSON string,
+// whatever key type the backing store uses (an auto-increment integer in
+// MySQL, a UUID in DynamoDB); repositories translate it to their native key.
+type CartID string
+
+// String returns the cart ID as a string
+func (id CartID) String() string {
+	return string(id)
+}
+
+// UnmarshalJSON accepts the canonical string form as well as a bare integer,
+// which older clients send because the spec originally declared an integer
+func (id *CartID) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = CartID(s)
+		return nil
+	}
+
+	n, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("shopping cart ID must be a string or an integer")
+	}
+	*id = CartID(strconv.FormatInt(n, 10))
+	return nil
+}
@@ -0,0 +1,23 @@
+package models
+
+// Dependency check statuses reported by the readiness endpoint
+const (
+	DependencyUp   = "up"
+	DependencyDown = "down"
+)
+
+// DependencyStatus is the outcome of probing one backing service
+type DependencyStatus struct {
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// ReadinessResponse represents the response of GET /health/ready
+type ReadinessResponse struct {
+	Status       string                      `json:"status"` // "ready", "not_ready" or "draining"
+	Timestamp    int64                       `json:"timestamp"`
+	Service      string                      `json:"service"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Inventory represents the warehouse stock of a single product
+type Inventory struct {
+	ProductID int       `json:"product_id" dynamodbav:"product_id"`
+	OnHand    int       `json:"on_hand" dynamodbav:"on_hand"`
+	Reserved  int       `json:"reserved" dynamodbav:"reserved"`
+	Available int       `json:"available" dynamodbav:"available"` // OnHand - Reserved
+	UpdatedAt time.Time `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+// InventoryRequest represents the request body for reserving, shipping or restocking a product
+type InventoryRequest struct {
+	ProductID int `json:"product_id" binding:"required,min=1"`
+	Quantity  int `json:"quantity" binding:"required,min=1"`
+}
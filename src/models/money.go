@@ -0,0 +1,13 @@
+package models
+
+// Money is an amount in the minor units of an ISO 4217 currency, e.g. cents
+// for USD, so prices are never subject to floating point rounding
+type Money struct {
+	Amount   int64  `json:"amount" dynamodbav:"amount" binding:"min=0"`
+	Currency string `json:"currency" dynamodbav:"currency" binding:"required,iso4217"`
+}
+
+// Times returns the amount multiplied by quantity in the same currency
+func (m Money) Times(quantity int) Money {
+	return Money{Amount: m.Amount * int64(quantity), Currency: m.Currency}
+}
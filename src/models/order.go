@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// OrderID identifies an order. Like CartID it is always serialized as a
+// string: an auto-increment integer in MySQL, a UUID in DynamoDB.
+type OrderID string
+
+// String returns the order ID as a string
+func (id OrderID) String() string {
+	return string(id)
+}
+
+// Order represents a checked-out shopping cart
+type Order struct {
+	OrderID    OrderID     `json:"order_id" dynamodbav:"order_id"`
+	CartID     CartID      `json:"shopping_cart_id" dynamodbav:"cart_id"`
+	CustomerID int         `json:"customer_id" dynamodbav:"customer_id"`
+	Items      []OrderItem `json:"items" dynamodbav:"order_items"`
+	CreatedAt  time.Time   `json:"created_at" dynamodbav:"created_at"`
+}
+
+// OrderItem is a snapshot of a cart item taken at checkout
+type OrderItem struct {
+	ProductID int `json:"product_id" dynamodbav:"product_id"`
+	Quantity  int `json:"quantity" dynamodbav:"quantity"`
+}
+
+// CheckoutResponse represents the response after checking out a cart
+type CheckoutResponse struct {
+	OrderID OrderID `json:"order_id"`
+}
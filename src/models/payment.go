@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Payment statuses
+const (
+	PaymentStatusApproved = "approved"
+	PaymentStatusDeclined = "declined"
+)
+
+// Payment represents a processed credit card transaction
+type Payment struct {
+	TransactionID string    `json:"transaction_id" dynamodbav:"transaction_id"`
+	CartID        CartID    `json:"shopping_cart_id" dynamodbav:"cart_id"`
+	CardLastFour  string    `json:"card_last_four" dynamodbav:"card_last_four"`
+	Status        string    `json:"status" dynamodbav:"status"`
+	DeclineReason string    `json:"decline_reason,omitempty" dynamodbav:"decline_reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at" dynamodbav:"created_at"`
+}
+
+// ProcessPaymentRequest represents the request body for processing a payment
+type ProcessPaymentRequest struct {
+	CreditCardNumber string `json:"credit_card_number" binding:"required"`
+	ShoppingCartID   CartID `json:"shopping_cart_id" binding:"required"`
+}
+
+// ProcessPaymentResponse represents the response after processing a payment
+type ProcessPaymentResponse struct {
+	Success       bool   `json:"success"`
+	TransactionID string `json:"transaction_id"`
+}
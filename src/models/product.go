@@ -1,11 +1,75 @@
 package models
 
-// Product represents the product schema from the OpenAPI spec
+// Product represents the product schema from the OpenAPI spec. The binding
+// tags mirror the spec's constraints; string lengths count characters.
 type Product struct {
-	ProductID    int    `json:"product_id" binding:"required,min=1"`
-	SKU          string `json:"sku" binding:"required"`
-	Manufacturer string `json:"manufacturer" binding:"required"`
+	ProductID    int    `json:"product_id" dynamodbav:"product_id" binding:"required,min=1"`
+	SKU          string `json:"sku" dynamodbav:"sku" binding:"required,min=1,max=100"`
+	Manufacturer string `json:"manufacturer" dynamodbav:"manufacturer" binding:"required,min=1,max=200"`
+	CategoryID   int    `json:"category_id" dynamodbav:"category_id" binding:"required,min=1"`
+	Weight       *int   `json:"weight" dynamodbav:"weight" binding:"required,min=0"` // a pointer so a missing weight is not read as 0
+	SomeOtherID  int    `json:"some_other_id" dynamodbav:"some_other_id" binding:"required,min=1"`
+	Price        *Money `json:"price,omitempty" dynamodbav:"price,omitempty"` // nil for a product without a price
+}
+
+// UpdateProductRequest represents the request body for replacing a product.
+// The product ID comes from the path.
+type UpdateProductRequest struct {
+	SKU          string `json:"sku" binding:"required,min=1,max=100"`
+	Manufacturer string `json:"manufacturer" binding:"required,min=1,max=200"`
 	CategoryID   int    `json:"category_id" binding:"required,min=1"`
-	Weight       int    `json:"weight" binding:"required,min=0"`
+	Weight       *int   `json:"weight" binding:"required,min=0"`
 	SomeOtherID  int    `json:"some_other_id" binding:"required,min=1"`
+	Price        *Money `json:"price"`
+}
+
+// PatchProductRequest represents the request body for partially updating a
+// product; omitted fields keep their current value
+type PatchProductRequest struct {
+	SKU          *string `json:"sku" binding:"omitempty,min=1,max=100"`
+	Manufacturer *string `json:"manufacturer" binding:"omitempty,min=1,max=200"`
+	CategoryID   *int    `json:"category_id" binding:"omitempty,min=1"`
+	Weight       *int    `json:"weight" binding:"omitempty,min=0"`
+	SomeOtherID  *int    `json:"some_other_id" binding:"omitempty,min=1"`
+	Price        *Money  `json:"price"`
+}
+
+// Apply copies the fields present in the patch onto p
+func (r PatchProductRequest) Apply(p *Product) {
+	if r.SKU != nil {
+		p.SKU = *r.SKU
+	}
+	if r.Manufacturer != nil {
+		p.Manufacturer = *r.Manufacturer
+	}
+	if r.CategoryID != nil {
+		p.CategoryID = *r.CategoryID
+	}
+	if r.Weight != nil {
+		p.Weight = r.Weight
+	}
+	if r.SomeOtherID != nil {
+		p.SomeOtherID = *r.SomeOtherID
+	}
+	if r.Price != nil {
+		p.Price = r.Price
+	}
+}
+
+// ListProductsQuery represents the query parameters for browsing the catalog
+type ListProductsQuery struct {
+	Manufacturer string `form:"manufacturer"`
+	CategoryID   int    `form:"category_id" binding:"omitempty,min=1"`
+	MinWeight    *int   `form:"min_weight" binding:"omitempty,min=0"`
+	MaxWeight    *int   `form:"max_weight" binding:"omitempty,min=0"`
+	SKUPrefix    string `form:"sku_prefix"`
+	Sort         string `form:"sort"` // field name, prefixed with "-" for descending
+	Limit        int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	NextToken    string `form:"next_token"`
+}
+
+// ListProductsResponse represents a page of the product catalog
+type ListProductsResponse struct {
+	Products  []Product `json:"products"`
+	NextToken string    `json:"next_token,omitempty"`
 }
@@ -0,0 +1,65 @@
+package payments
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// DeclineRule inspects a charge and returns a reason when it should be declined
+type DeclineRule func(req ChargeRequest) (reason string, declined bool)
+
+// DeclinePrefix declines every card number starting with prefix
+func DeclinePrefix(prefix string) DeclineRule {
+	return func(req ChargeRequest) (string, bool) {
+		if strings.HasPrefix(req.CardNumber, prefix) {
+			return "card declined by issuer", true
+		}
+		return "", false
+	}
+}
+
+// FakeProcessor is a deterministic local PaymentProcessor.
+// Cards failing the Luhn check are always declined; other cards are
+// declined only when one of the configured rules matches.
+type FakeProcessor struct {
+	rules []DeclineRule
+}
+
+// NewFakeProcessor creates a fake processor with the given decline rules
+func NewFakeProcessor(rules ...DeclineRule) *FakeProcessor {
+	return &FakeProcessor{rules: rules}
+}
+
+// Ensure FakeProcessor implements PaymentProcessor
+var _ PaymentProcessor = (*FakeProcessor)(nil)
+
+// Charge approves or declines the request without contacting any card network
+func (p *FakeProcessor) Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error) {
+	if err := ctx.Err(); err != nil {
+		return ChargeResult{}, err
+	}
+
+	result := ChargeResult{TransactionID: uuid.New().String()}
+
+	if !LuhnValid(req.CardNumber) {
+		result.DeclineReason = "invalid card number"
+		return result, nil
+	}
+
+	for _, rule := range p.rules {
+		if reason, declined := rule(req); declined {
+			result.DeclineReason = reason
+			return result, nil
+		}
+	}
+
+	result.Approved = true
+	return result, nil
+}
+
+// Refund accepts every refund; the fake processor keeps no balances
+func (p *FakeProcessor) Refund(ctx context.Context, transactionID string) error {
+	return ctx.Err()
+}
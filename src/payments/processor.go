@@ -0,0 +1,66 @@
+package payments
+
+import (
+	"context"
+	"regexp"
+
+	"store_product/models"
+)
+
+// cardNumberPattern matches the credit_card_number pattern from the API spec
+var cardNumberPattern = regexp.MustCompile(`^[0-9]{13,19}$`)
+
+// ChargeRequest describes a card charge
+type ChargeRequest struct {
+	CardNumber string
+	CartID     models.CartID
+	Amount     models.Money // the cart total
+}
+
+// ChargeResult is the outcome of a charge attempt
+type ChargeResult struct {
+	TransactionID string
+	Approved      bool
+	DeclineReason string
+}
+
+// PaymentProcessor charges credit cards
+type PaymentProcessor interface {
+	Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error)
+	// Refund returns the full amount of an approved charge
+	Refund(ctx context.Context, transactionID string) error
+}
+
+// ValidCardNumber reports whether the card number matches the spec's pattern
+func ValidCardNumber(number string) bool {
+	return cardNumberPattern.MatchString(number)
+}
+
+// LuhnValid reports whether the card number passes the Luhn checksum
+func LuhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// LastFour returns the last four digits of a card number for storage
+func LastFour(number string) string {
+	if len(number) < 4 {
+		return number
+	}
+	return number[len(number)-4:]
+}
@@ -1,9 +1,14 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"store_product/models"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // MySQLCartRepository handles shopping cart data operations for MySQL
@@ -20,38 +25,39 @@ func NewMySQLCartRepository(db *sql.DB) *MySQLCartRepository {
 var _ CartRepositoryInterface = (*MySQLCartRepository)(nil)
 
 // Create creates a new shopping cart
-func (r *MySQLCartRepository) Create(customerID int) (interface{}, error) {
-	result, err := r.db.Exec(
+func (r *MySQLCartRepository) Create(ctx context.Context, customerID int) (models.CartID, error) {
+	result, err := r.db.ExecContext(ctx,
 		"INSERT INTO shopping_carts (customer_id) VALUES (?)",
 		customerID,
 	)
 	if err != nil {
-		return 0, fmt.Errorf("failed to create cart: %w", err)
+		return "", fmt.Errorf("failed to create cart: %w", err)
 	}
 
 	cartID, err := result.LastInsertId()
 	if err != nil {
-		return 0, fmt.Errorf("failed to get cart ID: %w", err)
+		return "", fmt.Errorf("failed to get cart ID: %w", err)
 	}
 
-	return int(cartID), nil
+	return mysqlToCartID(int(cartID)), nil
 }
 
 // GetByID retrieves a shopping cart by ID with all items
-func (r *MySQLCartRepository) GetByID(cartID interface{}) (*models.ShoppingCart, error) {
-	id, ok := cartID.(int)
-	if !ok {
-		return nil, fmt.Errorf("invalid cart ID type for MySQL")
+func (r *MySQLCartRepository) GetByID(ctx context.Context, cartID models.CartID) (*models.ShoppingCart, error) {
+	id, err := mysqlCartID(cartID)
+	if err != nil {
+		return nil, err
 	}
 	// Use LEFT JOIN to get cart and all items in a single query
-	rows, err := r.db.Query(`
+	rows, err := r.db.QueryContext(ctx, `
 		SELECT 
-			c.cart_id, c.customer_id, c.created_at, c.updated_at,
-			ci.item_id, ci.product_id, ci.quantity, ci.added_at, ci.updated_at
+			c.cart_id, c.customer_id, c.status, c.created_at, c.updated_at,
+			ci.item_id, ci.product_id, ci.quantity, ci.added_at, ci.updated_at,
+			ci.price_amount, ci.price_currency
 		FROM shopping_carts c
 		LEFT JOIN cart_items ci ON c.cart_id = ci.cart_id
 		WHERE c.cart_id = ?
-		ORDER BY ci.added_at
+		ORDER BY ci.added_at, ci.item_id
 	`, id)
 
 	if err != nil {
@@ -64,26 +70,32 @@ func (r *MySQLCartRepository) GetByID(cartID interface{}) (*models.ShoppingCart,
 
 	for rows.Next() {
 		// Use sql.Null types for the cart_items fields since they may be NULL (LEFT JOIN)
-		var itemID, productID, quantity sql.NullInt64
+		var itemID, productID, quantity, priceAmount sql.NullInt64
 		var addedAt, updatedAt sql.NullTime
+		var priceCurrency sql.NullString
 
 		if cart == nil {
 			// First row - initialize the cart
+			var id int
 			cart = &models.ShoppingCart{Items: []models.CartItem{}}
 			err := rows.Scan(
-				&cart.CartID, &cart.CustomerID, &cart.CreatedAt, &cart.UpdatedAt,
+				&id, &cart.CustomerID, &cart.Status, &cart.CreatedAt, &cart.UpdatedAt,
 				&itemID, &productID, &quantity, &addedAt, &updatedAt,
+				&priceAmount, &priceCurrency,
 			)
 			if err != nil {
 				return nil, fmt.Errorf("failed to scan cart: %w", err)
 			}
+			cart.CartID = mysqlToCartID(id)
 		} else {
 			// Subsequent rows - only scan item fields (cart fields are the same)
 			var tempCartID, tempCustomerID int
+			var tempStatus string
 			var tempCreatedAt, tempUpdatedAt interface{}
 			err := rows.Scan(
-				&tempCartID, &tempCustomerID, &tempCreatedAt, &tempUpdatedAt,
+				&tempCartID, &tempCustomerID, &tempStatus, &tempCreatedAt, &tempUpdatedAt,
 				&itemID, &productID, &quantity, &addedAt, &updatedAt,
+				&priceAmount, &priceCurrency,
 			)
 			if err != nil {
 				return nil, fmt.Errorf("failed to scan cart item: %w", err)
@@ -93,11 +105,12 @@ func (r *MySQLCartRepository) GetByID(cartID interface{}) (*models.ShoppingCart,
 		// Add item to cart if it exists (not NULL from LEFT JOIN)
 		if itemID.Valid {
 			item := models.CartItem{
-				ItemID:    int(itemID.Int64),
-				ProductID: int(productID.Int64),
-				Quantity:  int(quantity.Int64),
-				AddedAt:   addedAt.Time,
-				UpdatedAt: updatedAt.Time,
+				ItemID:     int(itemID.Int64),
+				ProductID:  int(productID.Int64),
+				Quantity:   int(quantity.Int64),
+				AddedAt:    addedAt.Time,
+				UpdatedAt:  updatedAt.Time,
+				PriceAtAdd: nullMoney(priceAmount, priceCurrency),
 			}
 			cart.Items = append(cart.Items, item)
 		}
@@ -111,13 +124,13 @@ func (r *MySQLCartRepository) GetByID(cartID interface{}) (*models.ShoppingCart,
 }
 
 // Exists checks if a cart exists
-func (r *MySQLCartRepository) Exists(cartID interface{}) (bool, error) {
-	id, ok := cartID.(int)
-	if !ok {
-		return false, fmt.Errorf("invalid cart ID type for MySQL")
+func (r *MySQLCartRepository) Exists(ctx context.Context, cartID models.CartID) (bool, error) {
+	id, err := mysqlCartID(cartID)
+	if err != nil {
+		return false, err
 	}
 	var exists bool
-	err := r.db.QueryRow(
+	err = r.db.QueryRowContext(ctx,
 		"SELECT EXISTS(SELECT 1 FROM shopping_carts WHERE cart_id = ?)",
 		id,
 	).Scan(&exists)
@@ -128,26 +141,150 @@ func (r *MySQLCartRepository) Exists(cartID interface{}) (bool, error) {
 }
 
 // AddItem adds or updates an item in the cart
-func (r *MySQLCartRepository) AddItem(cartID interface{}, productID, quantity int) error {
-	id, ok := cartID.(int)
-	if !ok {
-		return fmt.Errorf("invalid cart ID type for MySQL")
+func (r *MySQLCartRepository) AddItem(ctx context.Context, cartID models.CartID, productID, quantity int, price *models.Money) error {
+	priceAmount, priceCurrency := moneyArgs(price)
+	return r.modifyItems(ctx, cartID, func(tx *sql.Tx, id int) error {
+		// Use INSERT ... ON DUPLICATE KEY UPDATE for upsert behavior
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO cart_items (cart_id, product_id, quantity, price_amount, price_currency)
+			VALUES (?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE 
+				quantity = quantity + VALUES(quantity),
+				updated_at = CURRENT_TIMESTAMP
+		`, id, productID, quantity, priceAmount, priceCurrency)
+
+		if err != nil {
+			return fmt.Errorf("failed to add item to cart: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateItemQuantity sets the quantity of an item already in the cart
+func (r *MySQLCartRepository) UpdateItemQuantity(ctx context.Context, cartID models.CartID, productID, quantity int) error {
+	return r.modifyItems(ctx, cartID, func(tx *sql.Tx, id int) error {
+		var exists bool
+		err := tx.QueryRowContext(ctx,
+			"SELECT EXISTS(SELECT 1 FROM cart_items WHERE cart_id = ? AND product_id = ?)",
+			id, productID,
+		).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check cart item: %w", err)
+		}
+		if !exists {
+			return ErrItemNotFound
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE cart_items SET quantity = ?, updated_at = CURRENT_TIMESTAMP WHERE cart_id = ? AND product_id = ?",
+			quantity, id, productID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update cart item: %w", err)
+		}
+		return nil
+	})
+}
+
+// RemoveItem removes a single item from the cart
+func (r *MySQLCartRepository) RemoveItem(ctx context.Context, cartID models.CartID, productID int) error {
+	return r.modifyItems(ctx, cartID, func(tx *sql.Tx, id int) error {
+		result, err := tx.ExecContext(ctx,
+			"DELETE FROM cart_items WHERE cart_id = ? AND product_id = ?",
+			id, productID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to remove cart item: %w", err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to remove cart item: %w", err)
+		}
+		if affected == 0 {
+			return ErrItemNotFound
+		}
+		return nil
+	})
+}
+
+// ClearItems removes all items from the cart
+func (r *MySQLCartRepository) ClearItems(ctx context.Context, cartID models.CartID) error {
+	return r.modifyItems(ctx, cartID, func(tx *sql.Tx, id int) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM cart_items WHERE cart_id = ?", id); err != nil {
+			return fmt.Errorf("failed to clear cart items: %w", err)
+		}
+		return nil
+	})
+}
+
+// Delete removes the cart and all of its items
+func (r *MySQLCartRepository) Delete(ctx context.Context, cartID models.CartID) error {
+	id, err := mysqlCartID(cartID)
+	if err != nil {
+		return err
 	}
-	// Use INSERT ... ON DUPLICATE KEY UPDATE for upsert behavior
-	_, err := r.db.Exec(`
-		INSERT INTO cart_items (cart_id, product_id, quantity)
-		VALUES (?, ?, ?)
-		ON DUPLICATE KEY UPDATE 
-			quantity = quantity + VALUES(quantity),
-			updated_at = CURRENT_TIMESTAMP
-	`, id, productID, quantity)
 
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to add item to cart: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Checked-out carts are referenced by their order and must be kept
+	if _, _, err := lockCart(ctx, tx, id); err != nil {
+		return err
 	}
 
-	// Update cart's updated_at timestamp
-	_, err = r.db.Exec(
+	// Payments keep a foreign key to their cart; Save locks the cart before
+	// inserting one, so none can appear until this transaction ends
+	var hasPayments bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM payments WHERE cart_id = ?)", id).Scan(&hasPayments); err != nil {
+		return fmt.Errorf("failed to check cart payments: %w", err)
+	}
+	if hasPayments {
+		return ErrCartHasPayments
+	}
+
+	// cart_items rows are removed by ON DELETE CASCADE
+	if _, err := tx.ExecContext(ctx, "DELETE FROM shopping_carts WHERE cart_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete cart: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// modifyItems runs fn inside a transaction holding the lock on an active,
+// unpaid cart and bumps the cart's updated_at timestamp when fn succeeds
+func (r *MySQLCartRepository) modifyItems(ctx context.Context, cartID models.CartID, fn func(tx *sql.Tx, id int) error) error {
+	id, err := mysqlCartID(cartID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, status, err := lockCart(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if status == models.CartStatusPaid {
+		return ErrCartPaid
+	}
+
+	if err := fn(tx, id); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
 		"UPDATE shopping_carts SET updated_at = CURRENT_TIMESTAMP WHERE cart_id = ?",
 		id,
 	)
@@ -155,28 +292,220 @@ func (r *MySQLCartRepository) AddItem(cartID interface{}, productID, quantity in
 		return fmt.Errorf("failed to update cart timestamp: %w", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
-// GetByCustomerID retrieves all carts for a customer
-func (r *MySQLCartRepository) GetByCustomerID(customerID int) ([]models.ShoppingCart, error) {
-	rows, err := r.db.Query(
-		"SELECT cart_id, customer_id, created_at, updated_at FROM shopping_carts WHERE customer_id = ? ORDER BY created_at DESC",
-		customerID,
-	)
+// mysqlCartCursor is the keyset position of the last cart on a page
+type mysqlCartCursor struct {
+	CreatedAt time.Time `json:"c"`
+	CartID    int       `json:"i"`
+}
+
+// GetByCustomerID retrieves a page of carts for a customer, newest first
+func (r *MySQLCartRepository) GetByCustomerID(ctx context.Context, customerID int, opts CartListOptions) ([]models.ShoppingCart, string, error) {
+	query := "SELECT cart_id, customer_id, status, created_at, updated_at FROM shopping_carts WHERE customer_id = ?"
+	args := []interface{}{customerID}
+
+	if opts.Cursor != "" {
+		var after mysqlCartCursor
+		if err := decodeCursor(opts.Cursor, &after); err != nil {
+			return nil, "", err
+		}
+		query += " AND (created_at < ? OR (created_at = ? AND cart_id < ?))"
+		args = append(args, after.CreatedAt, after.CreatedAt, after.CartID)
+	}
+
+	// Fetch one extra row to learn whether another page exists
+	query += " ORDER BY created_at DESC, cart_id DESC LIMIT ?"
+	args = append(args, opts.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch customer carts: %w", err)
+		return nil, "", fmt.Errorf("failed to fetch customer carts: %w", err)
 	}
 	defer rows.Close()
 
 	carts := []models.ShoppingCart{}
+	ids := []int{}
 	for rows.Next() {
 		var cart models.ShoppingCart
-		if err := rows.Scan(&cart.CartID, &cart.CustomerID, &cart.CreatedAt, &cart.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan cart: %w", err)
+		var id int
+		if err := rows.Scan(&id, &cart.CustomerID, &cart.Status, &cart.CreatedAt, &cart.UpdatedAt); err != nil {
+			return nil, "", fmt.Errorf("failed to scan cart: %w", err)
 		}
+		cart.CartID = mysqlToCartID(id)
 		carts = append(carts, cart)
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("error iterating cart rows: %w", err)
 	}
 
-	return carts, nil
+	var next string
+	if len(carts) > opts.Limit {
+		carts, ids = carts[:opts.Limit], ids[:opts.Limit]
+		last := carts[len(carts)-1]
+		next, err = encodeCursor(mysqlCartCursor{CreatedAt: last.CreatedAt, CartID: ids[len(ids)-1]})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+
+	if opts.IncludeItems && len(carts) > 0 {
+		if err := r.loadItems(ctx, carts, ids); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return carts, next, nil
+}
+
+// loadItems fills in the items of carts, whose IDs are given in the same order
+func (r *MySQLCartRepository) loadItems(ctx context.Context, carts []models.ShoppingCart, ids []int) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]interface{}, len(ids))
+	index := make(map[int]int, len(ids))
+	for i, id := range ids {
+		args[i] = id
+		index[id] = i
+		carts[i].Items = []models.CartItem{}
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT cart_id, item_id, product_id, quantity, added_at, updated_at, price_amount, price_currency
+		FROM cart_items
+		WHERE cart_id IN (`+placeholders+`)
+		ORDER BY added_at, item_id
+	`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to fetch cart items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cartID int
+		var item models.CartItem
+		var priceAmount sql.NullInt64
+		var priceCurrency sql.NullString
+		if err := rows.Scan(&cartID, &item.ItemID, &item.ProductID, &item.Quantity, &item.AddedAt, &item.UpdatedAt, &priceAmount, &priceCurrency); err != nil {
+			return fmt.Errorf("failed to scan cart item: %w", err)
+		}
+		item.PriceAtAdd = nullMoney(priceAmount, priceCurrency)
+		i := index[cartID]
+		carts[i].Items = append(carts[i].Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating cart item rows: %w", err)
+	}
+
+	return nil
+}
+
+// Checkout freezes the cart and snapshots its items into a new order
+func (r *MySQLCartRepository) Checkout(ctx context.Context, cartID models.CartID) (models.OrderID, error) {
+	id, err := mysqlCartID(cartID)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	customerID, _, err := lockCart(ctx, tx, id)
+	if err != nil {
+		return "", err
+	}
+
+	// Copy the cart items into a new order
+	result, err := tx.ExecContext(ctx,
+		"INSERT INTO orders (cart_id, customer_id) VALUES (?, ?)",
+		id, customerID,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create order: %w", err)
+	}
+
+	orderID, err := result.LastInsertId()
+	if err != nil {
+		return "", fmt.Errorf("failed to get order ID: %w", err)
+	}
+
+	result, err = tx.ExecContext(ctx, `
+		INSERT INTO order_items (order_id, product_id, quantity)
+		SELECT ?, product_id, quantity FROM cart_items
+		WHERE cart_id = ? AND quantity > 0
+	`, orderID, id)
+	if err != nil {
+		return "", fmt.Errorf("failed to create order items: %w", err)
+	}
+
+	copied, err := result.RowsAffected()
+	if err != nil {
+		return "", fmt.Errorf("failed to count order items: %w", err)
+	}
+	if copied == 0 {
+		return "", ErrCartEmpty
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE shopping_carts SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE cart_id = ?",
+		models.CartStatusCheckedOut, id,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to update cart status: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit checkout: %w", err)
+	}
+
+	return models.OrderID(strconv.FormatInt(orderID, 10)), nil
+}
+
+// lockCart locks a cart row that has not been checked out for the rest of the
+// transaction and returns its customer ID and status
+func lockCart(ctx context.Context, tx *sql.Tx, cartID int) (customerID int, status string, err error) {
+	err = tx.QueryRowContext(ctx,
+		"SELECT customer_id, status FROM shopping_carts WHERE cart_id = ? FOR UPDATE",
+		cartID,
+	).Scan(&customerID, &status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, "", ErrCartNotFound
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to lock cart: %w", err)
+	}
+	if status == models.CartStatusCheckedOut {
+		return 0, "", ErrCartCheckedOut
+	}
+	return customerID, status, nil
+}
+
+// nullMoney builds a price snapshot from nullable columns, or nil if absent
+func nullMoney(amount sql.NullInt64, currency sql.NullString) *models.Money {
+	if !amount.Valid || !currency.Valid {
+		return nil
+	}
+	return &models.Money{Amount: amount.Int64, Currency: currency.String}
+}
+
+// mysqlCartID decodes a CartID into the auto-increment key used by MySQL
+func mysqlCartID(cartID models.CartID) (int, error) {
+	id, err := strconv.Atoi(string(cartID))
+	if err != nil || id < 1 {
+		return 0, ErrInvalidCartID
+	}
+	return id, nil
+}
+
+// mysqlToCartID encodes a MySQL cart key as a CartID
+func mysqlToCartID(id int) models.CartID {
+	return models.CartID(strconv.Itoa(id))
 }
@@ -0,0 +1,49 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"store_product/migrations"
+	"store_product/repositories"
+	"store_product/repositories/carttest"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// mysqlTestEnv names a MySQL DSN for the tests, e.g.
+// "root:secret@tcp(localhost:3306)/store_test?parseTime=true". The schema is
+// migrated and the suite's carts are left in place, so use a scratch
+// database. Tests needing MySQL are skipped without it.
+const mysqlTestEnv = "MYSQL_TEST_DSN"
+
+// newMySQLTestDB opens and migrates the test database, or skips the test
+func newMySQLTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv(mysqlTestEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", mysqlTestEnv)
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatalf("opening MySQL: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := migrations.Up(context.Background(), db); err != nil {
+		t.Fatalf("migrating MySQL: %v", err)
+	}
+	return db
+}
+
+func TestMySQLCartRepository(t *testing.T) {
+	db := newMySQLTestDB(t)
+	repo := repositories.NewMySQLCartRepository(db)
+	payments := repositories.NewMySQLPaymentRepository(db)
+	if err := carttest.TestRepository(context.Background(), repo, payments); err != nil {
+		t.Fatal(err)
+	}
+}
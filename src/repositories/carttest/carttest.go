@@ -0,0 +1,844 @@
+// Package carttest implements a conformance suite for CartRepositoryInterface
+// implementations and the PaymentRepositoryInterface sharing their storage,
+// in the spirit of testing/fstest: it lives in a regular
+// package so the same checks can be run against every backend, from a test
+// binary or from cmd/cartconformance against a live database.
+//
+// The suite pins down the behavior handlers rely on:
+//   - new carts are active, timestamped and have a non-nil, empty Items list
+//   - adding a product already in the cart increments its quantity and keeps
+//     the price snapshot taken when it was first added
+//   - items come back in the order they were added, with IDs unique within
+//     the cart even after removals
+//   - unknown carts read as (nil, nil) and fail writes with ErrCartNotFound
+//   - malformed IDs fail with ErrInvalidCartID
+//   - an approved payment marks the cart paid and freezes its items, a cart
+//     has at most one approved payment even under concurrent saves, and a
+//     paid cart can still be checked out
+//   - checked-out carts are frozen, and carts with payments cannot be deleted
+//   - customer listings page through every cart exactly once, newest first,
+//     and only return a continuation token with a full page
+//   - concurrent AddItem calls on one cart never lose an update
+//
+// Every check uses customers and carts created by the suite itself, so it can
+// be pointed at a shared development database.
+package carttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"store_product/models"
+	"store_product/repositories"
+
+	"github.com/google/uuid"
+)
+
+// testPrice is the price snapshot passed to AddItem
+var testPrice = models.Money{Amount: 1999, Currency: "USD"}
+
+// concurrentWriters is the number of goroutines racing on one cart
+const concurrentWriters = 8
+
+// testCase is one named conformance check
+type testCase struct {
+	name string
+	run  func(s *suite) error
+}
+
+var testCases = []testCase{
+	{"Create", testCreate},
+	{"MissingCart", testMissingCart},
+	{"InvalidCartID", testInvalidCartID},
+	{"AddItem", testAddItem},
+	{"AddItemUpsert", testAddItemUpsert},
+	{"AddItemPriceSnapshot", testAddItemPriceSnapshot},
+	{"AddItemWithoutPrice", testAddItemWithoutPrice},
+	{"UpdateItemQuantity", testUpdateItemQuantity},
+	{"RemoveItem", testRemoveItem},
+	{"ItemIDsAfterRemove", testItemIDsAfterRemove},
+	{"ClearItems", testClearItems},
+	{"Delete", testDelete},
+	{"DeleteWithPayments", testDeleteWithPayments},
+	{"Checkout", testCheckout},
+	{"Payment", testPayment},
+	{"ConcurrentPayment", testConcurrentPayment},
+	{"GetByCustomerID", testGetByCustomerID},
+	{"GetByCustomerIDEmpty", testGetByCustomerIDEmpty},
+	{"GetByCustomerIDInvalidCursor", testGetByCustomerIDInvalidCursor},
+	{"ConcurrentAddItem", testConcurrentAddItem},
+}
+
+// TestRepository runs every conformance check against repo, and payments
+// recording payments for its carts, and returns an error describing each
+// check that failed, or nil if the repositories conform
+func TestRepository(ctx context.Context, repo repositories.CartRepositoryInterface, payments repositories.PaymentRepositoryInterface) error {
+	var errs []error
+	for _, tc := range testCases {
+		s := &suite{ctx: ctx, repo: repo, payments: payments}
+		if err := tc.run(s); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", tc.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// suite carries the repositories under test through a single check
+type suite struct {
+	ctx      context.Context
+	repo     repositories.CartRepositoryInterface
+	payments repositories.PaymentRepositoryInterface
+}
+
+// newCustomerID returns a customer ID unlikely to collide with real data
+// or with another run of the suite
+func newCustomerID() int {
+	return 1<<30 + rand.Intn(1<<30-1)
+}
+
+// newCart creates a cart for a fresh customer
+func (s *suite) newCart() (models.CartID, int, error) {
+	customerID := newCustomerID()
+	cartID, err := s.repo.Create(s.ctx, customerID)
+	if err != nil {
+		return "", 0, fmt.Errorf("Create: %w", err)
+	}
+	if cartID == "" {
+		return "", 0, errors.New("Create returned an empty cart ID")
+	}
+	return cartID, customerID, nil
+}
+
+// get reads a cart that must exist
+func (s *suite) get(cartID models.CartID) (*models.ShoppingCart, error) {
+	cart, err := s.repo.GetByID(s.ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("GetByID(%s): %w", cartID, err)
+	}
+	if cart == nil {
+		return nil, fmt.Errorf("GetByID(%s) returned no cart", cartID)
+	}
+	return cart, nil
+}
+
+// deletedCartID returns the ID of a cart that existed but has been deleted,
+// which is a well-formed but unknown ID for any backend
+func (s *suite) deletedCartID() (models.CartID, error) {
+	cartID, _, err := s.newCart()
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.Delete(s.ctx, cartID); err != nil {
+		return "", fmt.Errorf("Delete: %w", err)
+	}
+	return cartID, nil
+}
+
+// pay records a payment with the given status for the cart
+func (s *suite) pay(cartID models.CartID, status string) (*models.Payment, error) {
+	payment := &models.Payment{
+		TransactionID: uuid.New().String(),
+		CartID:        cartID,
+		CardLastFour:  "1111",
+		Status:        status,
+		CreatedAt:     time.Now().UTC().Truncate(time.Second),
+	}
+	return payment, s.payments.Save(s.ctx, payment)
+}
+
+// checkItems compares a cart's items, in order, against product/quantity pairs
+func checkItems(cart *models.ShoppingCart, want ...[2]int) error {
+	if cart.Items == nil {
+		return errors.New("Items is nil, want a non-nil slice")
+	}
+	if len(cart.Items) != len(want) {
+		return fmt.Errorf("got %d items, want %d: %+v", len(cart.Items), len(want), cart.Items)
+	}
+	seen := make(map[int]bool)
+	for i, item := range cart.Items {
+		if item.ProductID != want[i][0] || item.Quantity != want[i][1] {
+			return fmt.Errorf("item %d is product %d x%d, want product %d x%d",
+				i, item.ProductID, item.Quantity, want[i][0], want[i][1])
+		}
+		if seen[item.ItemID] {
+			return fmt.Errorf("item ID %d is used twice", item.ItemID)
+		}
+		seen[item.ItemID] = true
+		if item.AddedAt.IsZero() || item.UpdatedAt.IsZero() {
+			return fmt.Errorf("item %d is missing timestamps", i)
+		}
+	}
+	return nil
+}
+
+// expectError checks that err matches target
+func expectError(op string, err, target error) error {
+	if !errors.Is(err, target) {
+		return fmt.Errorf("%s returned %v, want %v", op, err, target)
+	}
+	return nil
+}
+
+func testCreate(s *suite) error {
+	cartID, customerID, err := s.newCart()
+	if err != nil {
+		return err
+	}
+	cart, err := s.get(cartID)
+	if err != nil {
+		return err
+	}
+	if cart.CartID != cartID {
+		return fmt.Errorf("CartID is %q, want %q", cart.CartID, cartID)
+	}
+	if cart.CustomerID != customerID {
+		return fmt.Errorf("CustomerID is %d, want %d", cart.CustomerID, customerID)
+	}
+	if cart.Status != models.CartStatusActive {
+		return fmt.Errorf("Status is %q, want %q", cart.Status, models.CartStatusActive)
+	}
+	if cart.CreatedAt.IsZero() || cart.UpdatedAt.IsZero() {
+		return errors.New("new cart is missing timestamps")
+	}
+	if err := checkItems(cart); err != nil {
+		return err
+	}
+
+	exists, err := s.repo.Exists(s.ctx, cartID)
+	if err != nil {
+		return fmt.Errorf("Exists: %w", err)
+	}
+	if !exists {
+		return errors.New("Exists returned false for a new cart")
+	}
+
+	other, _, err := s.newCart()
+	if err != nil {
+		return err
+	}
+	if other == cartID {
+		return fmt.Errorf("two carts were both given ID %q", cartID)
+	}
+	return nil
+}
+
+func testMissingCart(s *suite) error {
+	cartID, err := s.deletedCartID()
+	if err != nil {
+		return err
+	}
+
+	cart, err := s.repo.GetByID(s.ctx, cartID)
+	if err != nil || cart != nil {
+		return fmt.Errorf("GetByID returned (%v, %v), want (nil, nil)", cart, err)
+	}
+	exists, err := s.repo.Exists(s.ctx, cartID)
+	if err != nil || exists {
+		return fmt.Errorf("Exists returned (%v, %v), want (false, nil)", exists, err)
+	}
+
+	if err := expectError("AddItem", s.repo.AddItem(s.ctx, cartID, 1, 1, &testPrice), repositories.ErrCartNotFound); err != nil {
+		return err
+	}
+	if err := expectError("UpdateItemQuantity", s.repo.UpdateItemQuantity(s.ctx, cartID, 1, 1), repositories.ErrCartNotFound); err != nil {
+		return err
+	}
+	if err := expectError("RemoveItem", s.repo.RemoveItem(s.ctx, cartID, 1), repositories.ErrCartNotFound); err != nil {
+		return err
+	}
+	if err := expectError("ClearItems", s.repo.ClearItems(s.ctx, cartID), repositories.ErrCartNotFound); err != nil {
+		return err
+	}
+	if err := expectError("Delete", s.repo.Delete(s.ctx, cartID), repositories.ErrCartNotFound); err != nil {
+		return err
+	}
+	_, err = s.repo.Checkout(s.ctx, cartID)
+	return expectError("Checkout", err, repositories.ErrCartNotFound)
+}
+
+func testInvalidCartID(s *suite) error {
+	const cartID = models.CartID("not-a-cart-id")
+
+	_, err := s.repo.GetByID(s.ctx, cartID)
+	if err := expectError("GetByID", err, repositories.ErrInvalidCartID); err != nil {
+		return err
+	}
+	_, err = s.repo.Exists(s.ctx, cartID)
+	if err := expectError("Exists", err, repositories.ErrInvalidCartID); err != nil {
+		return err
+	}
+	if err := expectError("AddItem", s.repo.AddItem(s.ctx, cartID, 1, 1, &testPrice), repositories.ErrInvalidCartID); err != nil {
+		return err
+	}
+	if err := expectError("Delete", s.repo.Delete(s.ctx, cartID), repositories.ErrInvalidCartID); err != nil {
+		return err
+	}
+	_, err = s.repo.Checkout(s.ctx, cartID)
+	return expectError("Checkout", err, repositories.ErrInvalidCartID)
+}
+
+func testAddItem(s *suite) error {
+	cartID, _, err := s.newCart()
+	if err != nil {
+		return err
+	}
+	before, err := s.get(cartID)
+	if err != nil {
+		return err
+	}
+
+	for _, add := range [][2]int{{3, 2}, {1, 5}, {2, 1}} {
+		if err := s.repo.AddItem(s.ctx, cartID, add[0], add[1], &testPrice); err != nil {
+			return fmt.Errorf("AddItem(%d, %d): %w", add[0], add[1], err)
+		}
+	}
+
+	cart, err := s.get(cartID)
+	if err != nil {
+		return err
+	}
+	if cart.UpdatedAt.Before(before.UpdatedAt) {
+		return errors.New("UpdatedAt moved backwards after AddItem")
+	}
+	// Items keep insertion order, not product order
+	return checkItems(cart, [2]int{3, 2}, [2]int{1, 5}, [2]int{2, 1})
+}
+
+func testAddItemUpsert(s *suite) error {
+	cartID, _, err := s.newCart()
+	if err != nil {
+		return err
+	}
+	for _, add := range [][2]int{{1, 2}, {2, 1}, {1, 3}} {
+		if err := s.repo.AddItem(s.ctx, cartID, add[0], add[1], &testPrice); err != nil {
+			return fmt.Errorf("AddItem(%d, %d): %w", add[0], add[1], err)
+		}
+	}
+
+	cart, err := s.get(cartID)
+	if err != nil {
+		return err
+	}
+	// Re-adding a product increments it in place rather than appending a line
+	return checkItems(cart, [2]int{1, 5}, [2]int{2, 1})
+}
+
+func testAddItemPriceSnapshot(s *suite) error {
+	cartID, _, err := s.newCart()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.AddItem(s.ctx, cartID, 1, 1, &testPrice); err != nil {
+		return fmt.Errorf("AddItem: %w", err)
+	}
+	repriced := models.Money{Amount: testPrice.Amount + 500, Currency: testPrice.Currency}
+	if err := s.repo.AddItem(s.ctx, cartID, 1, 1, &repriced); err != nil {
+		return fmt.Errorf("AddItem: %w", err)
+	}
+
+	cart, err := s.get(cartID)
+	if err != nil {
+		return err
+	}
+	if err := checkItems(cart, [2]int{1, 2}); err != nil {
+		return err
+	}
+	if got := cart.Items[0].PriceAtAdd; got == nil || *got != testPrice {
+		return fmt.Errorf("PriceAtAdd is %v, want the first snapshot %v", got, testPrice)
+	}
+	return nil
+}
+
+func testAddItemWithoutPrice(s *suite) error {
+	cartID, _, err := s.newCart()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.AddItem(s.ctx, cartID, 1, 1, nil); err != nil {
+		return fmt.Errorf("AddItem: %w", err)
+	}
+
+	cart, err := s.get(cartID)
+	if err != nil {
+		return err
+	}
+	if err := checkItems(cart, [2]int{1, 1}); err != nil {
+		return err
+	}
+	if got := cart.Items[0].PriceAtAdd; got != nil {
+		return fmt.Errorf("PriceAtAdd is %v, want none for a product without a price", *got)
+	}
+	return nil
+}
+
+func testUpdateItemQuantity(s *suite) error {
+	cartID, _, err := s.newCart()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.AddItem(s.ctx, cartID, 1, 2, &testPrice); err != nil {
+		return fmt.Errorf("AddItem: %w", err)
+	}
+	if err := s.repo.UpdateItemQuantity(s.ctx, cartID, 1, 7); err != nil {
+		return fmt.Errorf("UpdateItemQuantity: %w", err)
+	}
+	if err := expectError("UpdateItemQuantity of a product not in the cart",
+		s.repo.UpdateItemQuantity(s.ctx, cartID, 2, 1), repositories.ErrItemNotFound); err != nil {
+		return err
+	}
+
+	cart, err := s.get(cartID)
+	if err != nil {
+		return err
+	}
+	return checkItems(cart, [2]int{1, 7})
+}
+
+func testRemoveItem(s *suite) error {
+	cartID, _, err := s.newCart()
+	if err != nil {
+		return err
+	}
+	for _, productID := range []int{1, 2, 3} {
+		if err := s.repo.AddItem(s.ctx, cartID, productID, productID, &testPrice); err != nil {
+			return fmt.Errorf("AddItem(%d): %w", productID, err)
+		}
+	}
+	if err := s.repo.RemoveItem(s.ctx, cartID, 2); err != nil {
+		return fmt.Errorf("RemoveItem: %w", err)
+	}
+	if err := expectError("RemoveItem of a product not in the cart",
+		s.repo.RemoveItem(s.ctx, cartID, 2), repositories.ErrItemNotFound); err != nil {
+		return err
+	}
+
+	cart, err := s.get(cartID)
+	if err != nil {
+		return err
+	}
+	return checkItems(cart, [2]int{1, 1}, [2]int{3, 3})
+}
+
+func testItemIDsAfterRemove(s *suite) error {
+	cartID, _, err := s.newCart()
+	if err != nil {
+		return err
+	}
+	steps := []func() error{
+		func() error { return s.repo.AddItem(s.ctx, cartID, 1, 1, &testPrice) },
+		func() error { return s.repo.AddItem(s.ctx, cartID, 2, 1, &testPrice) },
+		func() error { return s.repo.RemoveItem(s.ctx, cartID, 1) },
+		func() error { return s.repo.AddItem(s.ctx, cartID, 3, 1, &testPrice) },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+
+	cart, err := s.get(cartID)
+	if err != nil {
+		return err
+	}
+	// checkItems rejects duplicate item IDs
+	return checkItems(cart, [2]int{2, 1}, [2]int{3, 1})
+}
+
+func testClearItems(s *suite) error {
+	cartID, _, err := s.newCart()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.AddItem(s.ctx, cartID, 1, 1, &testPrice); err != nil {
+		return fmt.Errorf("AddItem: %w", err)
+	}
+	if err := s.repo.ClearItems(s.ctx, cartID); err != nil {
+		return fmt.Errorf("ClearItems: %w", err)
+	}
+	// Clearing an already empty cart is not an error
+	if err := s.repo.ClearItems(s.ctx, cartID); err != nil {
+		return fmt.Errorf("ClearItems of an empty cart: %w", err)
+	}
+
+	cart, err := s.get(cartID)
+	if err != nil {
+		return err
+	}
+	return checkItems(cart)
+}
+
+func testDelete(s *suite) error {
+	cartID, _, err := s.newCart()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.AddItem(s.ctx, cartID, 1, 1, &testPrice); err != nil {
+		return fmt.Errorf("AddItem: %w", err)
+	}
+	if err := s.repo.Delete(s.ctx, cartID); err != nil {
+		return fmt.Errorf("Delete: %w", err)
+	}
+
+	cart, err := s.repo.GetByID(s.ctx, cartID)
+	if err != nil || cart != nil {
+		return fmt.Errorf("GetByID after Delete returned (%v, %v), want (nil, nil)", cart, err)
+	}
+	return nil
+}
+
+func testDeleteWithPayments(s *suite) error {
+	for _, status := range []string{models.PaymentStatusDeclined, models.PaymentStatusApproved} {
+		cartID, _, err := s.newCart()
+		if err != nil {
+			return err
+		}
+		payment, err := s.pay(cartID, status)
+		if err != nil {
+			return fmt.Errorf("Save of a %s payment: %w", status, err)
+		}
+
+		err = s.repo.Delete(s.ctx, cartID)
+		if err := expectError(fmt.Sprintf("Delete of a cart with a %s payment", status), err, repositories.ErrCartHasPayments); err != nil {
+			return err
+		}
+		if _, err := s.get(cartID); err != nil {
+			return fmt.Errorf("after a refused Delete: %w", err)
+		}
+		stored, err := s.payments.GetByTransactionID(s.ctx, payment.TransactionID)
+		if err != nil || stored == nil {
+			return fmt.Errorf("GetByTransactionID after a refused Delete returned (%v, %v)", stored, err)
+		}
+	}
+	return nil
+}
+
+func testCheckout(s *suite) error {
+	cartID, _, err := s.newCart()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.repo.Checkout(s.ctx, cartID)
+	if err := expectError("Checkout of an empty cart", err, repositories.ErrCartEmpty); err != nil {
+		return err
+	}
+
+	if err := s.repo.AddItem(s.ctx, cartID, 1, 2, &testPrice); err != nil {
+		return fmt.Errorf("AddItem: %w", err)
+	}
+	orderID, err := s.repo.Checkout(s.ctx, cartID)
+	if err != nil {
+		return fmt.Errorf("Checkout: %w", err)
+	}
+	if orderID == "" {
+		return errors.New("Checkout returned an empty order ID")
+	}
+
+	cart, err := s.get(cartID)
+	if err != nil {
+		return err
+	}
+	if !cart.IsCheckedOut() {
+		return fmt.Errorf("Status after Checkout is %q, want %q", cart.Status, models.CartStatusCheckedOut)
+	}
+	if err := checkItems(cart, [2]int{1, 2}); err != nil {
+		return fmt.Errorf("after Checkout: %w", err)
+	}
+
+	// A checked-out cart is frozen
+	if err := expectError("AddItem", s.repo.AddItem(s.ctx, cartID, 2, 1, &testPrice), repositories.ErrCartCheckedOut); err != nil {
+		return err
+	}
+	if err := expectError("UpdateItemQuantity", s.repo.UpdateItemQuantity(s.ctx, cartID, 1, 1), repositories.ErrCartCheckedOut); err != nil {
+		return err
+	}
+	if err := expectError("RemoveItem", s.repo.RemoveItem(s.ctx, cartID, 1), repositories.ErrCartCheckedOut); err != nil {
+		return err
+	}
+	if err := expectError("ClearItems", s.repo.ClearItems(s.ctx, cartID), repositories.ErrCartCheckedOut); err != nil {
+		return err
+	}
+	if err := expectError("Delete", s.repo.Delete(s.ctx, cartID), repositories.ErrCartCheckedOut); err != nil {
+		return err
+	}
+	_, err = s.repo.Checkout(s.ctx, cartID)
+	return expectError("second Checkout", err, repositories.ErrCartCheckedOut)
+}
+
+func testPayment(s *suite) error {
+	cartID, _, err := s.newCart()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.AddItem(s.ctx, cartID, 1, 2, &testPrice); err != nil {
+		return fmt.Errorf("AddItem: %w", err)
+	}
+
+	// A declined payment leaves the cart open
+	if _, err := s.pay(cartID, models.PaymentStatusDeclined); err != nil {
+		return fmt.Errorf("Save of a declined payment: %w", err)
+	}
+	if err := s.repo.AddItem(s.ctx, cartID, 2, 1, &testPrice); err != nil {
+		return fmt.Errorf("AddItem after a declined payment: %w", err)
+	}
+
+	approved, err := s.pay(cartID, models.PaymentStatusApproved)
+	if err != nil {
+		return fmt.Errorf("Save of an approved payment: %w", err)
+	}
+	cart, err := s.get(cartID)
+	if err != nil {
+		return err
+	}
+	if !cart.IsPaid() {
+		return fmt.Errorf("Status after an approved payment is %q, want %q", cart.Status, models.CartStatusPaid)
+	}
+	paid, err := s.payments.GetApprovedByCartID(s.ctx, cartID)
+	if err != nil {
+		return fmt.Errorf("GetApprovedByCartID: %w", err)
+	}
+	if paid == nil || paid.TransactionID != approved.TransactionID {
+		return fmt.Errorf("GetApprovedByCartID returned %+v, want transaction %s", paid, approved.TransactionID)
+	}
+
+	// A paid cart is frozen and cannot be paid again
+	if err := expectError("AddItem", s.repo.AddItem(s.ctx, cartID, 3, 1, &testPrice), repositories.ErrCartPaid); err != nil {
+		return err
+	}
+	if err := expectError("UpdateItemQuantity", s.repo.UpdateItemQuantity(s.ctx, cartID, 1, 5), repositories.ErrCartPaid); err != nil {
+		return err
+	}
+	if err := expectError("RemoveItem", s.repo.RemoveItem(s.ctx, cartID, 1), repositories.ErrCartPaid); err != nil {
+		return err
+	}
+	if err := expectError("ClearItems", s.repo.ClearItems(s.ctx, cartID), repositories.ErrCartPaid); err != nil {
+		return err
+	}
+	_, err = s.pay(cartID, models.PaymentStatusApproved)
+	if err := expectError("Save of a second approved payment", err, repositories.ErrCartPaid); err != nil {
+		return err
+	}
+
+	// Checking out turns the paid contents into the order
+	if _, err := s.repo.Checkout(s.ctx, cartID); err != nil {
+		return fmt.Errorf("Checkout of a paid cart: %w", err)
+	}
+	cart, err = s.get(cartID)
+	if err != nil {
+		return err
+	}
+	if err := checkItems(cart, [2]int{1, 2}, [2]int{2, 1}); err != nil {
+		return fmt.Errorf("after Checkout: %w", err)
+	}
+	_, err = s.pay(cartID, models.PaymentStatusApproved)
+	return expectError("Save of a payment for a checked-out cart", err, repositories.ErrCartCheckedOut)
+}
+
+func testConcurrentPayment(s *suite) error {
+	cartID, _, err := s.newCart()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.AddItem(s.ctx, cartID, 1, 1, &testPrice); err != nil {
+		return fmt.Errorf("AddItem: %w", err)
+	}
+
+	// Every writer saves an approved payment; at most one may win
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		winners  []string
+		failures []error
+	)
+	for i := 0; i < concurrentWriters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			payment, err := s.pay(cartID, models.PaymentStatusApproved)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				winners = append(winners, payment.TransactionID)
+			case !errors.Is(err, repositories.ErrCartPaid) && !errors.Is(err, repositories.ErrConcurrentModification):
+				failures = append(failures, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return fmt.Errorf("concurrent Save failed: %w", errors.Join(failures...))
+	}
+	if len(winners) > 1 {
+		return fmt.Errorf("%d approved payments were saved for one cart", len(winners))
+	}
+
+	paid, err := s.payments.GetApprovedByCartID(s.ctx, cartID)
+	if err != nil {
+		return fmt.Errorf("GetApprovedByCartID: %w", err)
+	}
+	switch {
+	case len(winners) == 0 && paid != nil:
+		return fmt.Errorf("no Save succeeded but the cart has approved payment %s", paid.TransactionID)
+	case len(winners) == 1 && (paid == nil || paid.TransactionID != winners[0]):
+		return fmt.Errorf("GetApprovedByCartID returned %+v, want transaction %s", paid, winners[0])
+	}
+	return nil
+}
+
+func testGetByCustomerID(s *suite) error {
+	customerID := newCustomerID()
+	want := make(map[models.CartID]bool)
+	for i := 0; i < 5; i++ {
+		cartID, err := s.repo.Create(s.ctx, customerID)
+		if err != nil {
+			return fmt.Errorf("Create: %w", err)
+		}
+		want[cartID] = true
+	}
+	var withItems models.CartID
+	for cartID := range want {
+		withItems = cartID
+		break
+	}
+	if err := s.repo.AddItem(s.ctx, withItems, 1, 4, &testPrice); err != nil {
+		return fmt.Errorf("AddItem: %w", err)
+	}
+	// Another customer's cart must never show up
+	if _, _, err := s.newCart(); err != nil {
+		return err
+	}
+
+	for _, includeItems := range []bool{false, true} {
+		seen := make(map[models.CartID]bool)
+		var previous time.Time
+		cursor := ""
+		for page := 1; ; page++ {
+			if page > len(want)+1 {
+				return errors.New("pagination did not terminate")
+			}
+			carts, next, err := s.repo.GetByCustomerID(s.ctx, customerID, repositories.CartListOptions{
+				Limit:        2,
+				Cursor:       cursor,
+				IncludeItems: includeItems,
+			})
+			if err != nil {
+				return fmt.Errorf("GetByCustomerID page %d: %w", page, err)
+			}
+			if len(carts) > 2 {
+				return fmt.Errorf("page %d has %d carts, limit is 2", page, len(carts))
+			}
+			if next != "" && len(carts) < 2 {
+				return fmt.Errorf("page %d has %d carts and a continuation token, want a full page", page, len(carts))
+			}
+			for i := range carts {
+				cart := &carts[i]
+				if !want[cart.CartID] {
+					return fmt.Errorf("unexpected cart %q for customer %d", cart.CartID, customerID)
+				}
+				if seen[cart.CartID] {
+					return fmt.Errorf("cart %q returned twice", cart.CartID)
+				}
+				seen[cart.CartID] = true
+				if !previous.IsZero() && cart.CreatedAt.After(previous) {
+					return fmt.Errorf("cart %q created at %v is listed after a cart created at %v, want newest first",
+						cart.CartID, cart.CreatedAt, previous)
+				}
+				previous = cart.CreatedAt
+				if cart.CustomerID != customerID || cart.Status != models.CartStatusActive {
+					return fmt.Errorf("cart %q has customer %d status %q", cart.CartID, cart.CustomerID, cart.Status)
+				}
+
+				switch {
+				case !includeItems && cart.Items != nil:
+					return fmt.Errorf("cart %q has items without IncludeItems", cart.CartID)
+				case includeItems && cart.CartID == withItems:
+					if err := checkItems(cart, [2]int{1, 4}); err != nil {
+						return fmt.Errorf("cart %q: %w", cart.CartID, err)
+					}
+				case includeItems:
+					if err := checkItems(cart); err != nil {
+						return fmt.Errorf("cart %q: %w", cart.CartID, err)
+					}
+				}
+			}
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		if len(seen) != len(want) {
+			return fmt.Errorf("listing returned %d of %d carts (IncludeItems=%v)", len(seen), len(want), includeItems)
+		}
+	}
+	return nil
+}
+
+func testGetByCustomerIDEmpty(s *suite) error {
+	carts, next, err := s.repo.GetByCustomerID(s.ctx, newCustomerID(), repositories.CartListOptions{Limit: 10})
+	if err != nil {
+		return fmt.Errorf("GetByCustomerID: %w", err)
+	}
+	if carts == nil || len(carts) != 0 || next != "" {
+		return fmt.Errorf("got (%v, %q), want an empty non-nil slice and no cursor", carts, next)
+	}
+	return nil
+}
+
+func testGetByCustomerIDInvalidCursor(s *suite) error {
+	_, _, err := s.repo.GetByCustomerID(s.ctx, newCustomerID(), repositories.CartListOptions{
+		Limit:  10,
+		Cursor: "not a cursor!",
+	})
+	return expectError("GetByCustomerID", err, repositories.ErrInvalidCursor)
+}
+
+func testConcurrentAddItem(s *suite) error {
+	cartID, _, err := s.newCart()
+	if err != nil {
+		return err
+	}
+
+	// Every writer adds one unit of the same product; backends may reject
+	// writes under contention, but every accepted write must be counted
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+		failures  []error
+	)
+	for i := 0; i < concurrentWriters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.repo.AddItem(s.ctx, cartID, 1, 1, &testPrice)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				succeeded++
+			case !errors.Is(err, repositories.ErrConcurrentModification):
+				failures = append(failures, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return fmt.Errorf("concurrent AddItem failed: %w", errors.Join(failures...))
+	}
+	if succeeded == 0 {
+		return errors.New("every concurrent AddItem was rejected")
+	}
+
+	cart, err := s.get(cartID)
+	if err != nil {
+		return err
+	}
+	return checkItems(cart, [2]int{1, succeeded})
+}
@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// encodeCursor turns a backend-specific position into an opaque continuation token
+func encodeCursor(position interface{}) (string, error) {
+	raw, err := json.Marshal(position)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeCursor parses a continuation token produced by encodeCursor
+func decodeCursor(token string, position interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, position); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
@@ -2,7 +2,10 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand"
+	"strconv"
 	"time"
 
 	"store_product/models"
@@ -16,15 +19,20 @@ import (
 
 // DynamoDBCartRepository handles shopping cart data operations for DynamoDB
 type DynamoDBCartRepository struct {
-	client    *dynamodb.Client
-	tableName string
+	client          *dynamodb.Client
+	tableName       string
+	ordersTableName string
+	ttl             time.Duration
 }
 
-// NewDynamoDBCartRepository creates a new DynamoDB cart repository
-func NewDynamoDBCartRepository(client *dynamodb.Client, tableName string) *DynamoDBCartRepository {
+// NewDynamoDBCartRepository creates a new DynamoDB cart repository whose
+// carts expire ttl after creation
+func NewDynamoDBCartRepository(client *dynamodb.Client, tableName, ordersTableName string, ttl time.Duration) *DynamoDBCartRepository {
 	return &DynamoDBCartRepository{
-		client:    client,
-		tableName: tableName,
+		client:          client,
+		tableName:       tableName,
+		ordersTableName: ordersTableName,
+		ttl:             ttl,
 	}
 }
 
@@ -32,14 +40,16 @@ func NewDynamoDBCartRepository(client *dynamodb.Client, tableName string) *Dynam
 var _ CartRepositoryInterface = (*DynamoDBCartRepository)(nil)
 
 // Create creates a new shopping cart
-func (r *DynamoDBCartRepository) Create(customerID int) (interface{}, error) {
-	cartID := uuid.New().String()
+func (r *DynamoDBCartRepository) Create(ctx context.Context, customerID int) (models.CartID, error) {
+	cartID := models.CartID(uuid.New().String())
 	now := time.Now()
-	ttl := now.Add(24 * time.Hour).Unix() // 24 hours from now
+	ttl := now.Add(r.ttl).Unix()
 
 	cart := models.ShoppingCart{
 		CartID:     cartID,
 		CustomerID: customerID,
+		Status:     models.CartStatusActive,
+		Version:    1,
 		CreatedAt:  now,
 		UpdatedAt:  now,
 		Items:      []models.CartItem{},
@@ -49,30 +59,31 @@ func (r *DynamoDBCartRepository) Create(customerID int) (interface{}, error) {
 	// Marshal the cart to DynamoDB attribute values
 	av, err := attributevalue.MarshalMap(cart)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal cart: %w", err)
+		return "", fmt.Errorf("failed to marshal cart: %w", err)
 	}
+	av["created_at"] = &types.AttributeValueMemberS{Value: now.UTC().Format(createdAtLayout)}
 
 	// Put item into DynamoDB
-	_, err = r.client.PutItem(context.TODO(), &dynamodb.PutItemInput{
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
 		TableName: aws.String(r.tableName),
 		Item:      av,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create cart in DynamoDB: %w", err)
+		return "", fmt.Errorf("failed to create cart in DynamoDB: %w", err)
 	}
 
 	return cartID, nil
 }
 
 // GetByID retrieves a shopping cart by ID with all items
-func (r *DynamoDBCartRepository) GetByID(cartID interface{}) (*models.ShoppingCart, error) {
-	id, ok := cartID.(string)
-	if !ok {
-		return nil, fmt.Errorf("invalid cart ID type for DynamoDB")
+func (r *DynamoDBCartRepository) GetByID(ctx context.Context, cartID models.CartID) (*models.ShoppingCart, error) {
+	id, err := dynamoCartID(cartID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get item from DynamoDB with strong consistency
-	result, err := r.client.GetItem(context.TODO(), &dynamodb.GetItemInput{
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
 		TableName: aws.String(r.tableName),
 		Key: map[string]types.AttributeValue{
 			"cart_id": &types.AttributeValueMemberS{Value: id},
@@ -99,8 +110,8 @@ func (r *DynamoDBCartRepository) GetByID(cartID interface{}) (*models.ShoppingCa
 }
 
 // Exists checks if a cart exists
-func (r *DynamoDBCartRepository) Exists(cartID interface{}) (bool, error) {
-	cart, err := r.GetByID(cartID)
+func (r *DynamoDBCartRepository) Exists(ctx context.Context, cartID models.CartID) (bool, error) {
+	cart, err := r.GetByID(ctx, cartID)
 	if err != nil {
 		return false, err
 	}
@@ -108,43 +119,165 @@ func (r *DynamoDBCartRepository) Exists(cartID interface{}) (bool, error) {
 }
 
 // AddItem adds or updates an item in the cart
-func (r *DynamoDBCartRepository) AddItem(cartID interface{}, productID, quantity int) error {
-	id, ok := cartID.(string)
-	if !ok {
-		return fmt.Errorf("invalid cart ID type for DynamoDB")
+func (r *DynamoDBCartRepository) AddItem(ctx context.Context, cartID models.CartID, productID, quantity int, price *models.Money) error {
+	return r.modifyItems(ctx, cartID, func(cart *models.ShoppingCart) error {
+		// Check if product already exists in items
+		for i, item := range cart.Items {
+			if item.ProductID == productID {
+				// Increment quantity
+				cart.Items[i].Quantity += quantity
+				cart.Items[i].UpdatedAt = time.Now()
+				return nil
+			}
+		}
+
+		// If not found, append new item
+		newItem := models.CartItem{
+			ItemID:     cart.NextItemID(),
+			ProductID:  productID,
+			Quantity:   quantity,
+			PriceAtAdd: price,
+			AddedAt:    time.Now(),
+			UpdatedAt:  time.Now(),
+		}
+		cart.Items = append(cart.Items, newItem)
+		return nil
+	})
+}
+
+// UpdateItemQuantity sets the quantity of an item already in the cart
+func (r *DynamoDBCartRepository) UpdateItemQuantity(ctx context.Context, cartID models.CartID, productID, quantity int) error {
+	return r.modifyItems(ctx, cartID, func(cart *models.ShoppingCart) error {
+		for i, item := range cart.Items {
+			if item.ProductID == productID {
+				cart.Items[i].Quantity = quantity
+				cart.Items[i].UpdatedAt = time.Now()
+				return nil
+			}
+		}
+		return ErrItemNotFound
+	})
+}
+
+// RemoveItem removes a single item from the cart
+func (r *DynamoDBCartRepository) RemoveItem(ctx context.Context, cartID models.CartID, productID int) error {
+	return r.modifyItems(ctx, cartID, func(cart *models.ShoppingCart) error {
+		for i, item := range cart.Items {
+			if item.ProductID == productID {
+				cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+				return nil
+			}
+		}
+		return ErrItemNotFound
+	})
+}
+
+// ClearItems removes all items from the cart
+func (r *DynamoDBCartRepository) ClearItems(ctx context.Context, cartID models.CartID) error {
+	return r.modifyItems(ctx, cartID, func(cart *models.ShoppingCart) error {
+		cart.Items = []models.CartItem{}
+		return nil
+	})
+}
+
+// Delete removes the cart and all of its items
+func (r *DynamoDBCartRepository) Delete(ctx context.Context, cartID models.CartID) error {
+	id, err := dynamoCartID(cartID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"cart_id": &types.AttributeValueMemberS{Value: id},
+		},
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":checked_out": &types.AttributeValueMemberS{Value: models.CartStatusCheckedOut},
+		},
+		// Checked-out carts are referenced by their order and carts with
+		// payments by those payments; both must be kept
+		ConditionExpression: aws.String("attribute_exists(cart_id) AND (attribute_not_exists(#status) OR #status <> :checked_out) AND attribute_not_exists(payment_attempts)"),
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			cart, getErr := r.GetByID(ctx, cartID)
+			if getErr != nil {
+				return getErr
+			}
+			switch {
+			case cart == nil:
+				return ErrCartNotFound
+			case cart.IsCheckedOut():
+				return ErrCartCheckedOut
+			}
+			return ErrCartHasPayments
+		}
+		return fmt.Errorf("failed to delete cart from DynamoDB: %w", err)
+	}
+
+	return nil
+}
+
+// maxItemWriteAttempts bounds how often modifyItems retries after losing a
+// race with another writer of the same cart
+const maxItemWriteAttempts = 5
+
+// errVersionConflict signals that the cart changed between read and write
+var errVersionConflict = errors.New("cart version conflict")
+
+// modifyItems loads an active, unpaid cart, lets fn change its items and writes the
+// items list back. The write is conditioned on the cart's version, so a
+// concurrent writer makes it fail instead of silently losing an update; the
+// read-modify-write is then retried against the fresh cart.
+func (r *DynamoDBCartRepository) modifyItems(ctx context.Context, cartID models.CartID, fn func(cart *models.ShoppingCart) error) error {
+	id, err := dynamoCartID(cartID)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := r.tryModifyItems(ctx, id, fn)
+		if !errors.Is(err, errVersionConflict) {
+			return err
+		}
+		if attempt == maxItemWriteAttempts {
+			return ErrConcurrentModification
+		}
+
+		// Back off with jitter so competing writers spread out
+		backoff := time.Duration(attempt)*10*time.Millisecond + time.Duration(rand.Int63n(int64(10*time.Millisecond)))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
 	}
+}
 
+// tryModifyItems performs a single versioned read-modify-write of the cart items
+func (r *DynamoDBCartRepository) tryModifyItems(ctx context.Context, id string, fn func(cart *models.ShoppingCart) error) error {
 	// First, get the current cart
-	cart, err := r.GetByID(id)
+	cart, err := r.GetByID(ctx, models.CartID(id))
 	if err != nil {
 		return fmt.Errorf("failed to get cart: %w", err)
 	}
 	if cart == nil {
-		return fmt.Errorf("cart not found")
+		return ErrCartNotFound
 	}
-
-	// Check if product already exists in items
-	found := false
-	for i, item := range cart.Items {
-		if item.ProductID == productID {
-			// Increment quantity
-			cart.Items[i].Quantity += quantity
-			cart.Items[i].UpdatedAt = time.Now()
-			found = true
-			break
-		}
+	if cart.IsCheckedOut() {
+		return ErrCartCheckedOut
+	}
+	if cart.IsPaid() {
+		return ErrCartPaid
 	}
 
-	// If not found, append new item
-	if !found {
-		newItem := models.CartItem{
-			ItemID:    len(cart.Items) + 1, // Simple incrementing ID
-			ProductID: productID,
-			Quantity:  quantity,
-			AddedAt:   time.Now(),
-			UpdatedAt: time.Now(),
-		}
-		cart.Items = append(cart.Items, newItem)
+	if err := fn(cart); err != nil {
+		return err
 	}
 
 	// Update the cart's updated_at timestamp
@@ -161,47 +294,239 @@ func (r *DynamoDBCartRepository) AddItem(cartID interface{}, productID, quantity
 		return fmt.Errorf("failed to marshal updated_at: %w", err)
 	}
 
+	condition, values := versionCondition(cart.Version)
+	values[":cart_items"] = itemsAV
+	values[":updated_at"] = updatedAtAV
+
 	// Update the cart in DynamoDB
-	_, err = r.client.UpdateItem(context.TODO(), &dynamodb.UpdateItemInput{
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
 		TableName: aws.String(r.tableName),
 		Key: map[string]types.AttributeValue{
 			"cart_id": &types.AttributeValueMemberS{Value: id},
 		},
-		UpdateExpression: aws.String("SET cart_items = :cart_items, updated_at = :updated_at"),
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":cart_items": itemsAV,
-			":updated_at": updatedAtAV,
+		UpdateExpression: aws.String("SET cart_items = :cart_items, updated_at = :updated_at, #version = :next_version"),
+		ExpressionAttributeNames: map[string]string{
+			"#version": "version",
 		},
-		ConditionExpression: aws.String("attribute_exists(cart_id)"), // Ensure cart exists
+		ExpressionAttributeValues: values,
+		// The version only matches if nobody else has written the cart since we
+		// read it, which also covers deletes and checkouts in the meantime
+		ConditionExpression: aws.String(condition),
 	})
 	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return errVersionConflict
+		}
 		return fmt.Errorf("failed to update cart in DynamoDB: %w", err)
 	}
 
 	return nil
 }
 
-// GetByCustomerID retrieves all carts for a customer using GSI
-func (r *DynamoDBCartRepository) GetByCustomerID(customerID int) ([]models.ShoppingCart, error) {
-	// Query using the customer-index GSI
-	result, err := r.client.Query(context.TODO(), &dynamodb.QueryInput{
+// versionCondition returns the condition expression matching a cart read at
+// the given version, plus expression values including :next_version.
+// Carts written before versioning was introduced have no version attribute.
+func versionCondition(version int) (string, map[string]types.AttributeValue) {
+	values := map[string]types.AttributeValue{
+		":next_version": &types.AttributeValueMemberN{Value: strconv.Itoa(version + 1)},
+	}
+	if version == 0 {
+		return "attribute_exists(cart_id) AND attribute_not_exists(#version)", values
+	}
+	values[":version"] = &types.AttributeValueMemberN{Value: strconv.Itoa(version)}
+	return "#version = :version", values
+}
+
+// createdAtLayout is RFC 3339 with a fixed-width fraction. The customer-index
+// sorts by created_at as a string, which the trimmed fraction of
+// time.RFC3339Nano would misorder.
+const createdAtLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// dynamoCartCursor holds the customer-index key of the last cart on a page
+type dynamoCartCursor struct {
+	CartID     string `json:"i" dynamodbav:"cart_id"`
+	CustomerID int    `json:"c" dynamodbav:"customer_id"`
+	CreatedAt  string `json:"t" dynamodbav:"created_at"` // as stored, so the key matches exactly
+}
+
+// GetByCustomerID retrieves a page of carts for a customer, newest first, by
+// querying the customer-index GSI
+func (r *DynamoDBCartRepository) GetByCustomerID(ctx context.Context, customerID int, opts CartListOptions) ([]models.ShoppingCart, string, error) {
+	input := &dynamodb.QueryInput{
 		TableName:              aws.String(r.tableName),
 		IndexName:              aws.String("customer-index"),
 		KeyConditionExpression: aws.String("customer_id = :customer_id"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":customer_id": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", customerID)},
 		},
-	})
+		ScanIndexForward: aws.Bool(false),
+	}
+
+	if !opts.IncludeItems {
+		input.ProjectionExpression = aws.String("cart_id, customer_id, #status, created_at, updated_at, #ttl")
+		input.ExpressionAttributeNames = map[string]string{
+			"#status": "status",
+			"#ttl":    "ttl",
+		}
+	}
+
+	if opts.Cursor != "" {
+		var after dynamoCartCursor
+		if err := decodeCursor(opts.Cursor, &after); err != nil {
+			return nil, "", err
+		}
+		if after.CustomerID != customerID {
+			return nil, "", ErrInvalidCursor
+		}
+		input.ExclusiveStartKey = map[string]types.AttributeValue{
+			"cart_id":     &types.AttributeValueMemberS{Value: after.CartID},
+			"customer_id": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", after.CustomerID)},
+			"created_at":  &types.AttributeValueMemberS{Value: after.CreatedAt},
+		}
+	}
+
+	// A query page can stop short of its limit, so keep reading until the
+	// page is full. One cart beyond it tells whether another page exists.
+	var items []map[string]types.AttributeValue
+	for {
+		if opts.Limit > 0 {
+			input.Limit = aws.Int32(int32(opts.Limit + 1 - len(items)))
+		}
+		result, err := r.client.Query(ctx, input)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to query carts by customer ID: %w", err)
+		}
+		items = append(items, result.Items...)
+
+		if result.LastEvaluatedKey == nil || (opts.Limit > 0 && len(items) > opts.Limit) {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+
+	var next string
+	if opts.Limit > 0 && len(items) > opts.Limit {
+		items = items[:opts.Limit]
+		var after dynamoCartCursor
+		if err := attributevalue.UnmarshalMap(items[len(items)-1], &after); err != nil {
+			return nil, "", fmt.Errorf("failed to unmarshal cart key: %w", err)
+		}
+		var err error
+		next, err = encodeCursor(after)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+
+	carts := []models.ShoppingCart{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &carts); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal carts: %w", err)
+	}
+	return carts, next, nil
+}
+
+// Checkout freezes the cart and snapshots its items into a new order.
+// The cart update and the order insert are written in a single transaction,
+// conditioned on the cart version not having changed since it was read.
+func (r *DynamoDBCartRepository) Checkout(ctx context.Context, cartID models.CartID) (models.OrderID, error) {
+	id, err := dynamoCartID(cartID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query carts by customer ID: %w", err)
+		return "", err
 	}
 
-	// Unmarshal the results
-	var carts []models.ShoppingCart
-	err = attributevalue.UnmarshalListOfMaps(result.Items, &carts)
+	cart, err := r.GetByID(ctx, cartID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal carts: %w", err)
+		return "", fmt.Errorf("failed to get cart: %w", err)
+	}
+	if cart == nil {
+		return "", ErrCartNotFound
+	}
+	if cart.IsCheckedOut() {
+		return "", ErrCartCheckedOut
+	}
+
+	// Snapshot the cart items
+	items := make([]models.OrderItem, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		if item.Quantity > 0 {
+			items = append(items, models.OrderItem{ProductID: item.ProductID, Quantity: item.Quantity})
+		}
+	}
+	if len(items) == 0 {
+		return "", ErrCartEmpty
+	}
+
+	now := time.Now()
+	order := models.Order{
+		OrderID:    models.OrderID(uuid.New().String()),
+		CartID:     cartID,
+		CustomerID: cart.CustomerID,
+		Items:      items,
+		CreatedAt:  now,
+	}
+
+	orderAV, err := attributevalue.MarshalMap(order)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal order: %w", err)
 	}
 
-	return carts, nil
+	nowAV, err := attributevalue.Marshal(now)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal updated_at: %w", err)
+	}
+
+	condition, values := versionCondition(cart.Version)
+	values[":checked_out"] = &types.AttributeValueMemberS{Value: models.CartStatusCheckedOut}
+	values[":now"] = nowAV
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Update: &types.Update{
+					TableName: aws.String(r.tableName),
+					Key: map[string]types.AttributeValue{
+						"cart_id": &types.AttributeValueMemberS{Value: id},
+					},
+					UpdateExpression: aws.String("SET #status = :checked_out, updated_at = :now, #version = :next_version"),
+					ExpressionAttributeNames: map[string]string{
+						"#status":  "status",
+						"#version": "version",
+					},
+					ExpressionAttributeValues: values,
+					// Fails if items were changed, or the cart checked out, since it was read
+					ConditionExpression: aws.String(condition),
+				},
+			},
+			{
+				Put: &types.Put{
+					TableName:           aws.String(r.ordersTableName),
+					Item:                orderAV,
+					ConditionExpression: aws.String("attribute_not_exists(order_id)"),
+				},
+			},
+		},
+	})
+	if err != nil {
+		var canceled *types.TransactionCanceledException
+		if errors.As(err, &canceled) {
+			// The cart changed between the read and the write; report why if we can
+			if current, getErr := r.GetByID(ctx, cartID); getErr == nil && current != nil && current.IsCheckedOut() {
+				return "", ErrCartCheckedOut
+			}
+			return "", ErrConcurrentModification
+		}
+		return "", fmt.Errorf("failed to checkout cart in DynamoDB: %w", err)
+	}
+
+	return order.OrderID, nil
+}
+
+// dynamoCartID decodes a CartID into the UUID partition key used by DynamoDB
+func dynamoCartID(cartID models.CartID) (string, error) {
+	id, err := uuid.Parse(string(cartID))
+	if err != nil {
+		return "", ErrInvalidCartID
+	}
+	return id.String(), nil
 }
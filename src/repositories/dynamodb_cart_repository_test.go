@@ -0,0 +1,129 @@
+package repositories_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"store_product/repositories"
+	"store_product/repositories/carttest"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoDBLocalEnv names the DynamoDB Local endpoint, e.g.
+// http://localhost:8000. Tests needing DynamoDB are skipped without it. A
+// dedicated variable rather than AWS_ENDPOINT_URL keeps them from ever
+// reaching a real account.
+const dynamoDBLocalEnv = "DYNAMODB_LOCAL_ENDPOINT"
+
+// newDynamoDBLocalClient returns a client for DynamoDB Local, or skips the test
+func newDynamoDBLocalClient(t *testing.T) *dynamodb.Client {
+	t.Helper()
+	endpoint := os.Getenv(dynamoDBLocalEnv)
+	if endpoint == "" {
+		t.Skipf("%s is not set", dynamoDBLocalEnv)
+	}
+	return dynamodb.New(dynamodb.Options{
+		Region:       "us-west-2",
+		BaseEndpoint: aws.String(endpoint),
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "local", SecretAccessKey: "local"}, nil
+		}),
+	})
+}
+
+// attribute is a key attribute of a test table or index
+type attribute struct {
+	name string
+	typ  types.ScalarAttributeType
+}
+
+// index is a GSI of a test table, projecting all attributes
+type index struct {
+	name     string
+	hashKey  attribute
+	rangeKey *attribute
+}
+
+// createTable creates a table with a fresh name and the given hash key, and
+// deletes it when the test ends
+func createTable(t *testing.T, client *dynamodb.Client, prefix string, hashKey attribute, indexes ...index) string {
+	t.Helper()
+	ctx := context.Background()
+	name := fmt.Sprintf("%s-test-%d", prefix, time.Now().UnixNano())
+
+	input := &dynamodb.CreateTableInput{
+		TableName:   aws.String(name),
+		BillingMode: types.BillingModePayPerRequest,
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String(hashKey.name), AttributeType: hashKey.typ},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String(hashKey.name), KeyType: types.KeyTypeHash},
+		},
+	}
+	defined := map[string]bool{hashKey.name: true}
+	define := func(a attribute) {
+		if !defined[a.name] {
+			defined[a.name] = true
+			input.AttributeDefinitions = append(input.AttributeDefinitions,
+				types.AttributeDefinition{AttributeName: aws.String(a.name), AttributeType: a.typ})
+		}
+	}
+	for _, idx := range indexes {
+		define(idx.hashKey)
+		keys := []types.KeySchemaElement{
+			{AttributeName: aws.String(idx.hashKey.name), KeyType: types.KeyTypeHash},
+		}
+		if idx.rangeKey != nil {
+			define(*idx.rangeKey)
+			keys = append(keys, types.KeySchemaElement{AttributeName: aws.String(idx.rangeKey.name), KeyType: types.KeyTypeRange})
+		}
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, types.GlobalSecondaryIndex{
+			IndexName:  aws.String(idx.name),
+			KeySchema:  keys,
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		})
+	}
+
+	if _, err := client.CreateTable(ctx, input); err != nil {
+		t.Fatalf("CreateTable(%s): %v", name, err)
+	}
+	t.Cleanup(func() {
+		if _, err := client.DeleteTable(context.Background(), &dynamodb.DeleteTableInput{TableName: aws.String(name)}); err != nil {
+			t.Logf("DeleteTable(%s): %v", name, err)
+		}
+	})
+
+	waiter := dynamodb.NewTableExistsWaiter(client)
+	if err := waiter.Wait(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(name)}, time.Minute); err != nil {
+		t.Fatalf("waiting for table %s: %v", name, err)
+	}
+	return name
+}
+
+// newDynamoDBRepositories returns cart and payment repositories on fresh
+// DynamoDB Local tables shaped like those in terraform/modules/dynamodb
+func newDynamoDBRepositories(t *testing.T) (*repositories.DynamoDBCartRepository, *repositories.DynamoDBPaymentRepository) {
+	t.Helper()
+	client := newDynamoDBLocalClient(t)
+	carts := createTable(t, client, "carts", attribute{"cart_id", types.ScalarAttributeTypeS},
+		index{name: "customer-index", hashKey: attribute{"customer_id", types.ScalarAttributeTypeN}, rangeKey: &attribute{"created_at", types.ScalarAttributeTypeS}})
+	orders := createTable(t, client, "orders", attribute{"order_id", types.ScalarAttributeTypeS})
+	payments := createTable(t, client, "payments", attribute{"transaction_id", types.ScalarAttributeTypeS},
+		index{name: "cart-index", hashKey: attribute{"cart_id", types.ScalarAttributeTypeS}})
+	return repositories.NewDynamoDBCartRepository(client, carts, orders, time.Hour),
+		repositories.NewDynamoDBPaymentRepository(client, payments, carts)
+}
+
+func TestDynamoDBCartRepository(t *testing.T) {
+	repo, payments := newDynamoDBRepositories(t)
+	if err := carttest.TestRepository(context.Background(), repo, payments); err != nil {
+		t.Fatal(err)
+	}
+}
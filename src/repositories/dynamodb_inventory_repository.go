@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"store_product/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDBInventoryRepository handles warehouse inventory operations for DynamoDB.
+// Each item stores on_hand, reserved and a denormalized available counter, because
+// condition expressions cannot compute on_hand - reserved themselves.
+type DynamoDBInventoryRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// NewDynamoDBInventoryRepository creates a new DynamoDB inventory repository
+func NewDynamoDBInventoryRepository(client *dynamodb.Client, tableName string) *DynamoDBInventoryRepository {
+	return &DynamoDBInventoryRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+// Ensure DynamoDBInventoryRepository implements InventoryRepositoryInterface
+var _ InventoryRepositoryInterface = (*DynamoDBInventoryRepository)(nil)
+
+// Get retrieves the inventory of a product, or nil if it has never been stocked
+func (r *DynamoDBInventoryRepository) Get(ctx context.Context, productID int) (*models.Inventory, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(r.tableName),
+		Key:            inventoryKey(productID),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory from DynamoDB: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var inv models.Inventory
+	if err := attributevalue.UnmarshalMap(result.Item, &inv); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal inventory: %w", err)
+	}
+	return &inv, nil
+}
+
+// Restock adds on-hand stock for a product, creating the item if needed
+func (r *DynamoDBInventoryRepository) Restock(ctx context.Context, productID, quantity int) error {
+	return r.update(ctx, productID, quantity,
+		"SET on_hand = if_not_exists(on_hand, :zero) + :qty, "+
+			"available = if_not_exists(available, :zero) + :qty, "+
+			"reserved = if_not_exists(reserved, :zero), updated_at = :now",
+		"", nil)
+}
+
+// Reserve moves available stock into reserved stock
+func (r *DynamoDBInventoryRepository) Reserve(ctx context.Context, productID, quantity int) error {
+	return r.update(ctx, productID, quantity,
+		"SET available = available - :qty, reserved = reserved + :qty, updated_at = :now",
+		"available >= :qty", ErrInsufficientInventory)
+}
+
+// Ship removes reserved stock from the warehouse
+func (r *DynamoDBInventoryRepository) Ship(ctx context.Context, productID, quantity int) error {
+	return r.update(ctx, productID, quantity,
+		"SET reserved = reserved - :qty, on_hand = on_hand - :qty, updated_at = :now",
+		"reserved >= :qty", ErrInsufficientReserved)
+}
+
+// update applies a single conditional UpdateItem, translating a failed
+// condition into condErr
+func (r *DynamoDBInventoryRepository) update(ctx context.Context, productID, quantity int, expr, cond string, condErr error) error {
+	nowAV, err := attributevalue.Marshal(time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to marshal updated_at: %w", err)
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:        aws.String(r.tableName),
+		Key:              inventoryKey(productID),
+		UpdateExpression: aws.String(expr),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":qty": &types.AttributeValueMemberN{Value: strconv.Itoa(quantity)},
+			":now": nowAV,
+		},
+	}
+	if cond != "" {
+		// A missing item fails the comparison, so unknown stock is treated as zero
+		input.ConditionExpression = aws.String(cond)
+	} else {
+		input.ExpressionAttributeValues[":zero"] = &types.AttributeValueMemberN{Value: "0"}
+	}
+
+	_, err = r.client.UpdateItem(ctx, input)
+	if err != nil {
+		var ccf *types.ConditionalCheckFailedException
+		if condErr != nil && errors.As(err, &ccf) {
+			return condErr
+		}
+		return fmt.Errorf("failed to update inventory in DynamoDB: %w", err)
+	}
+	return nil
+}
+
+func inventoryKey(productID int) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"product_id": &types.AttributeValueMemberN{Value: strconv.Itoa(productID)},
+	}
+}
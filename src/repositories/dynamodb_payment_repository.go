@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"store_product/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDBPaymentRepository handles payment transaction storage for DynamoDB.
+// Payments also update their cart, so it needs the carts table as well.
+type DynamoDBPaymentRepository struct {
+	client         *dynamodb.Client
+	tableName      string
+	cartsTableName string
+}
+
+// NewDynamoDBPaymentRepository creates a new DynamoDB payment repository
+func NewDynamoDBPaymentRepository(client *dynamodb.Client, tableName, cartsTableName string) *DynamoDBPaymentRepository {
+	return &DynamoDBPaymentRepository{
+		client:         client,
+		tableName:      tableName,
+		cartsTableName: cartsTableName,
+	}
+}
+
+// Ensure DynamoDBPaymentRepository implements PaymentRepositoryInterface
+var _ PaymentRepositoryInterface = (*DynamoDBPaymentRepository)(nil)
+
+// Save records a payment transaction together with a conditional write to
+// its cart, in a single transaction. For an approved payment the cart write
+// moves an active cart to paid, so only one approved payment per cart can
+// ever succeed; other payments only require the cart to be open.
+func (r *DynamoDBPaymentRepository) Save(ctx context.Context, payment *models.Payment) error {
+	cartID, err := dynamoCartID(payment.CartID)
+	if err != nil {
+		return err
+	}
+
+	av, err := attributevalue.MarshalMap(payment)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payment: %w", err)
+	}
+
+	key := map[string]types.AttributeValue{
+		"cart_id": &types.AttributeValueMemberS{Value: cartID},
+	}
+	// Both writes count the payment on the cart, which keeps Delete from
+	// orphaning it
+	cartWrite := types.TransactWriteItem{
+		Update: &types.Update{
+			TableName:        aws.String(r.cartsTableName),
+			Key:              key,
+			UpdateExpression: aws.String("ADD payment_attempts :one"),
+			ExpressionAttributeNames: map[string]string{
+				"#status": "status",
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":checked_out": &types.AttributeValueMemberS{Value: models.CartStatusCheckedOut},
+				":one":         &types.AttributeValueMemberN{Value: "1"},
+			},
+			ConditionExpression: aws.String("attribute_exists(cart_id) AND (attribute_not_exists(#status) OR #status <> :checked_out)"),
+		},
+	}
+	if payment.Status == models.PaymentStatusApproved {
+		updatedAt, err := attributevalue.Marshal(payment.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to marshal updated_at: %w", err)
+		}
+		cartWrite = types.TransactWriteItem{
+			Update: &types.Update{
+				TableName: aws.String(r.cartsTableName),
+				Key:       key,
+				// The version bump makes concurrent item writes, which are
+				// conditioned on the version they read, retry and see the paid cart
+				UpdateExpression: aws.String("SET #status = :paid, updated_at = :updated_at, #version = if_not_exists(#version, :zero) + :one ADD payment_attempts :one"),
+				ExpressionAttributeNames: map[string]string{
+					"#status":  "status",
+					"#version": "version",
+				},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":active":     &types.AttributeValueMemberS{Value: models.CartStatusActive},
+					":paid":       &types.AttributeValueMemberS{Value: models.CartStatusPaid},
+					":updated_at": updatedAt,
+					":zero":       &types.AttributeValueMemberN{Value: "0"},
+					":one":        &types.AttributeValueMemberN{Value: "1"},
+				},
+				ConditionExpression: aws.String("attribute_exists(cart_id) AND (attribute_not_exists(#status) OR #status = :active)"),
+			},
+		}
+	}
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Put: &types.Put{
+					TableName:           aws.String(r.tableName),
+					Item:                av,
+					ConditionExpression: aws.String("attribute_not_exists(transaction_id)"),
+				},
+			},
+			cartWrite,
+		},
+	})
+	if err != nil {
+		var canceled *types.TransactionCanceledException
+		if errors.As(err, &canceled) {
+			return r.cartConflict(ctx, cartID)
+		}
+		return fmt.Errorf("failed to save payment in DynamoDB: %w", err)
+	}
+	return nil
+}
+
+// cartConflict explains a canceled payment transaction by the state of the
+// cart. A cart that would accept the payment lost a race with another write.
+func (r *DynamoDBPaymentRepository) cartConflict(ctx context.Context, cartID string) error {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.cartsTableName),
+		Key: map[string]types.AttributeValue{
+			"cart_id": &types.AttributeValueMemberS{Value: cartID},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get cart from DynamoDB: %w", err)
+	}
+	if result.Item == nil {
+		return ErrCartNotFound
+	}
+
+	var cart models.ShoppingCart
+	if err := attributevalue.UnmarshalMap(result.Item, &cart); err != nil {
+		return fmt.Errorf("failed to unmarshal cart: %w", err)
+	}
+	switch {
+	case cart.IsCheckedOut():
+		return ErrCartCheckedOut
+	case cart.IsPaid():
+		return ErrCartPaid
+	}
+	return ErrConcurrentModification
+}
+
+// GetByTransactionID retrieves a payment by transaction ID
+func (r *DynamoDBPaymentRepository) GetByTransactionID(ctx context.Context, transactionID string) (*models.Payment, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"transaction_id": &types.AttributeValueMemberS{Value: transactionID},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment from DynamoDB: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var payment models.Payment
+	if err := attributevalue.UnmarshalMap(result.Item, &payment); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payment: %w", err)
+	}
+	return &payment, nil
+}
+
+// GetApprovedByCartID retrieves an approved payment for a cart using the
+// cart-index GSI. A cart has few payment attempts, so all are read and
+// filtered.
+func (r *DynamoDBPaymentRepository) GetApprovedByCartID(ctx context.Context, cartID models.CartID) (*models.Payment, error) {
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("cart-index"),
+		KeyConditionExpression: aws.String("cart_id = :cart_id"),
+		FilterExpression:       aws.String("#status = :approved"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":cart_id":  &types.AttributeValueMemberS{Value: cartID.String()},
+			":approved": &types.AttributeValueMemberS{Value: models.PaymentStatusApproved},
+		},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query payments by cart ID: %w", err)
+		}
+		if len(page.Items) == 0 {
+			continue
+		}
+
+		var payment models.Payment
+		if err := attributevalue.UnmarshalMap(page.Items[0], &payment); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal payment: %w", err)
+		}
+		return &payment, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,569 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"store_product/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDBProductRepository handles product data operations for DynamoDB.
+// SKU uniqueness is enforced by a second table holding one reservation item
+// per SKU, written in the same transaction as the product. Products written
+// before reservations existed get theirs on their next update; likewise
+// products are only listed once they carry their catalog index keys.
+type DynamoDBProductRepository struct {
+	client       *dynamodb.Client
+	tableName    string
+	skuTableName string
+}
+
+// NewDynamoDBProductRepository creates a new DynamoDB product repository
+func NewDynamoDBProductRepository(client *dynamodb.Client, tableName, skuTableName string) *DynamoDBProductRepository {
+	return &DynamoDBProductRepository{
+		client:       client,
+		tableName:    tableName,
+		skuTableName: skuTableName,
+	}
+}
+
+// Ensure DynamoDBProductRepository implements ProductRepositoryInterface
+var _ ProductRepositoryInterface = (*DynamoDBProductRepository)(nil)
+
+// GetByID retrieves a product by ID
+func (r *DynamoDBProductRepository) GetByID(ctx context.Context, id int) (*models.Product, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       productKey(id),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product from DynamoDB: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var product models.Product
+	if err := attributevalue.UnmarshalMap(result.Item, &product); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal product: %w", err)
+	}
+	return &product, nil
+}
+
+// batchGetLimit is the most keys BatchGetItem accepts in one call
+const batchGetLimit = 100
+
+// GetByIDs retrieves several products with BatchGetItem
+func (r *DynamoDBProductRepository) GetByIDs(ctx context.Context, ids []int) ([]models.Product, error) {
+	// BatchGetItem rejects duplicate keys
+	var keys []map[string]types.AttributeValue
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			keys = append(keys, productKey(id))
+		}
+	}
+
+	products := []models.Product{}
+	for len(keys) > 0 {
+		batch := keys[:min(len(keys), batchGetLimit)]
+		keys = keys[len(batch):]
+
+		result, err := r.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+			RequestItems: map[string]types.KeysAndAttributes{
+				r.tableName: {Keys: batch},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get products from DynamoDB: %w", err)
+		}
+
+		var page []models.Product
+		if err := attributevalue.UnmarshalListOfMaps(result.Responses[r.tableName], &page); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal products: %w", err)
+		}
+		products = append(products, page...)
+
+		// Keys DynamoDB could not read within its limits are retried
+		if unprocessed, ok := result.UnprocessedKeys[r.tableName]; ok {
+			keys = append(keys, unprocessed.Keys...)
+		}
+	}
+	return products, nil
+}
+
+// GetBySKU retrieves a product by SKU through its reservation
+func (r *DynamoDBProductRepository) GetBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	owner, ok, err := r.skuOwner(ctx, sku)
+	if err != nil || !ok {
+		return nil, err
+	}
+	product, err := r.GetByID(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	// Ignore a reservation that no longer matches its product
+	if product == nil || product.SKU != sku {
+		return nil, nil
+	}
+	return product, nil
+}
+
+// Create stores a new product and reserves its SKU
+func (r *DynamoDBProductRepository) Create(ctx context.Context, product models.Product) error {
+	av, err := marshalProduct(product)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Put: &types.Put{
+					TableName:           aws.String(r.tableName),
+					Item:                av,
+					ConditionExpression: aws.String("attribute_not_exists(product_id)"),
+				},
+			},
+			r.reserveSKU(product),
+		},
+	})
+	if err != nil {
+		switch failed := canceledItems(err); {
+		case failed[0]:
+			return ErrProductExists
+		case failed[1]:
+			return ErrDuplicateSKU
+		}
+		return fmt.Errorf("failed to create product in DynamoDB: %w", err)
+	}
+	return nil
+}
+
+// maxProductWriteAttempts bounds how often Update and Delete retry after the
+// product changed between their read and their write
+const maxProductWriteAttempts = 3
+
+// Update replaces an existing product, moving its SKU reservation if the SKU changed
+func (r *DynamoDBProductRepository) Update(ctx context.Context, product models.Product) error {
+	return r.retryProductWrite(func() error { return r.tryUpdate(ctx, product) })
+}
+
+// tryUpdate performs a single update conditioned on the SKU read beforehand
+func (r *DynamoDBProductRepository) tryUpdate(ctx context.Context, product models.Product) error {
+	current, err := r.GetByID(ctx, product.ProductID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return ErrProductNotFound
+	}
+
+	av, err := marshalProduct(product)
+	if err != nil {
+		return err
+	}
+
+	items := []types.TransactWriteItem{
+		{
+			Put: &types.Put{
+				TableName:                 aws.String(r.tableName),
+				Item:                      av,
+				ConditionExpression:       aws.String("sku = :current_sku"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":current_sku": &types.AttributeValueMemberS{Value: current.SKU}},
+			},
+		},
+		// Also backfills the reservation of products that predate reservations
+		r.reserveSKU(product),
+	}
+	if current.SKU != product.SKU {
+		release, err := r.releaseSKU(ctx, *current)
+		if err != nil {
+			return err
+		}
+		if release != nil {
+			items = append(items, *release)
+		}
+	}
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	if err != nil {
+		failed := canceledItems(err)
+		switch {
+		case failed[1]:
+			return ErrDuplicateSKU
+		case failed[0] || failed[2]:
+			return errVersionConflict
+		}
+		return fmt.Errorf("failed to update product in DynamoDB: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a product and releases its SKU
+func (r *DynamoDBProductRepository) Delete(ctx context.Context, id int) error {
+	return r.retryProductWrite(func() error { return r.tryDelete(ctx, id) })
+}
+
+// tryDelete performs a single delete conditioned on the SKU read beforehand
+func (r *DynamoDBProductRepository) tryDelete(ctx context.Context, id int) error {
+	current, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return ErrProductNotFound
+	}
+
+	items := []types.TransactWriteItem{
+		{
+			Delete: &types.Delete{
+				TableName:                 aws.String(r.tableName),
+				Key:                       productKey(id),
+				ConditionExpression:       aws.String("sku = :current_sku"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":current_sku": &types.AttributeValueMemberS{Value: current.SKU}},
+			},
+		},
+	}
+	release, err := r.releaseSKU(ctx, *current)
+	if err != nil {
+		return err
+	}
+	if release != nil {
+		items = append(items, *release)
+	}
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	if err != nil {
+		if failed := canceledItems(err); failed[0] || failed[1] {
+			return errVersionConflict
+		}
+		return fmt.Errorf("failed to delete product from DynamoDB: %w", err)
+	}
+	return nil
+}
+
+// retryProductWrite repeats a read-then-write while it loses races with other writers
+func (r *DynamoDBProductRepository) retryProductWrite(write func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := write()
+		if !errors.Is(err, errVersionConflict) {
+			return err
+		}
+		if attempt == maxProductWriteAttempts {
+			return ErrConcurrentModification
+		}
+	}
+}
+
+// reserveSKU claims the product's SKU, unless another product holds it
+func (r *DynamoDBProductRepository) reserveSKU(product models.Product) types.TransactWriteItem {
+	return types.TransactWriteItem{
+		Put: &types.Put{
+			TableName: aws.String(r.skuTableName),
+			Item: map[string]types.AttributeValue{
+				"sku":        &types.AttributeValueMemberS{Value: product.SKU},
+				"product_id": &types.AttributeValueMemberN{Value: strconv.Itoa(product.ProductID)},
+			},
+			ConditionExpression: aws.String("attribute_not_exists(sku) OR product_id = :product_id"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":product_id": &types.AttributeValueMemberN{Value: strconv.Itoa(product.ProductID)},
+			},
+		},
+	}
+}
+
+// releaseSKU returns the write dropping the product's SKU reservation, or nil
+// if the product holds none
+func (r *DynamoDBProductRepository) releaseSKU(ctx context.Context, product models.Product) (*types.TransactWriteItem, error) {
+	owner, ok, err := r.skuOwner(ctx, product.SKU)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || owner != product.ProductID {
+		return nil, nil
+	}
+	return &types.TransactWriteItem{
+		Delete: &types.Delete{
+			TableName: aws.String(r.skuTableName),
+			Key: map[string]types.AttributeValue{
+				"sku": &types.AttributeValueMemberS{Value: product.SKU},
+			},
+			ConditionExpression: aws.String("product_id = :product_id"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":product_id": &types.AttributeValueMemberN{Value: strconv.Itoa(product.ProductID)},
+			},
+		},
+	}, nil
+}
+
+// skuOwner returns the ID of the product holding a SKU reservation
+func (r *DynamoDBProductRepository) skuOwner(ctx context.Context, sku string) (int, bool, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.skuTableName),
+		Key: map[string]types.AttributeValue{
+			"sku": &types.AttributeValueMemberS{Value: sku},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get SKU reservation from DynamoDB: %w", err)
+	}
+	if result.Item == nil {
+		return 0, false, nil
+	}
+
+	var reservation struct {
+		ProductID int `dynamodbav:"product_id"`
+	}
+	if err := attributevalue.UnmarshalMap(result.Item, &reservation); err != nil {
+		return 0, false, fmt.Errorf("failed to unmarshal SKU reservation: %w", err)
+	}
+	return reservation.ProductID, true, nil
+}
+
+// canceledItems reports, per transaction item, whether its condition failed.
+// It is empty unless err is a canceled transaction.
+func canceledItems(err error) map[int]bool {
+	failed := map[int]bool{}
+	var canceled *types.TransactionCanceledException
+	if errors.As(err, &canceled) {
+		for i, reason := range canceled.CancellationReasons {
+			if aws.ToString(reason.Code) == "ConditionalCheckFailed" {
+				failed[i] = true
+			}
+		}
+	}
+	return failed
+}
+
+// Exists checks if a product exists
+func (r *DynamoDBProductRepository) Exists(ctx context.Context, id int) (bool, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:            aws.String(r.tableName),
+		Key:                  productKey(id),
+		ProjectionExpression: aws.String("product_id"),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check product existence: %w", err)
+	}
+	return result.Item != nil, nil
+}
+
+// List retrieves a filtered, sorted page of products by querying every shard
+// of the catalog index of the sort field and merging the results. Filters are
+// applied to the items read, so reading stops once the page is full rather
+// than covering the whole catalog.
+func (r *DynamoDBProductRepository) List(ctx context.Context, opts ProductListOptions) ([]models.Product, string, error) {
+	cursor, err := parseProductCursor(opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	index := catalogIndexes[opts.sortBy()]
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String(index.name),
+		KeyConditionExpression: aws.String("#catalog = :catalog"),
+		ScanIndexForward:       aws.Bool(!opts.Descending),
+	}
+	names := map[string]string{"#catalog": catalogAttribute}
+	values := map[string]types.AttributeValue{}
+
+	var conditions []string
+	f := opts.Filter
+	if f.Manufacturer != "" {
+		conditions = append(conditions, "manufacturer = :manufacturer")
+		values[":manufacturer"] = &types.AttributeValueMemberS{Value: f.Manufacturer}
+	}
+	if f.CategoryID != 0 {
+		conditions = append(conditions, "category_id = :category_id")
+		values[":category_id"] = &types.AttributeValueMemberN{Value: strconv.Itoa(f.CategoryID)}
+	}
+	if f.MinWeight != nil {
+		conditions = append(conditions, "#weight >= :min_weight")
+		names["#weight"] = "weight"
+		values[":min_weight"] = &types.AttributeValueMemberN{Value: strconv.Itoa(*f.MinWeight)}
+	}
+	if f.MaxWeight != nil {
+		conditions = append(conditions, "#weight <= :max_weight")
+		names["#weight"] = "weight"
+		values[":max_weight"] = &types.AttributeValueMemberN{Value: strconv.Itoa(*f.MaxWeight)}
+	}
+	if f.SKUPrefix != "" {
+		conditions = append(conditions, "begins_with(sku, :sku_prefix)")
+		values[":sku_prefix"] = &types.AttributeValueMemberS{Value: f.SKUPrefix}
+	}
+	if len(conditions) > 0 {
+		input.FilterExpression = aws.String(strings.Join(conditions, " AND "))
+	}
+	input.ExpressionAttributeNames = names
+
+	shards := make([]*catalogShardReader, catalogShards)
+	for shard := range shards {
+		shardInput := *input
+		shardInput.ExpressionAttributeValues = maps.Clone(values)
+		shardInput.ExpressionAttributeValues[":catalog"] = catalogPartition(shard)
+		if cursor != nil {
+			shardInput.ExclusiveStartKey = index.key(shard, cursor.Text, cursor.Number, cursor.ProductID)
+		}
+		shards[shard] = &catalogShardReader{client: r.client, input: &shardInput, filtered: len(conditions) > 0}
+	}
+
+	// Each shard is already in listing order, so the next product is the
+	// first of whichever shard comes first. One product beyond the page
+	// tells whether another page exists.
+	products := []models.Product{}
+	for opts.Limit == 0 || len(products) <= opts.Limit {
+		want := 0
+		if opts.Limit > 0 {
+			want = opts.Limit + 1 - len(products)
+		}
+		var next *catalogShardReader
+		for _, shard := range shards {
+			if err := shard.fill(ctx, want); err != nil {
+				return nil, "", err
+			}
+			if len(shard.buffered) > 0 && (next == nil || compareProducts(shard.buffered[0], next.buffered[0], opts) < 0) {
+				next = shard
+			}
+		}
+		if next == nil {
+			break
+		}
+		products = append(products, next.buffered[0])
+		next.buffered = next.buffered[1:]
+	}
+
+	var next string
+	if opts.Limit > 0 && len(products) > opts.Limit {
+		products = products[:opts.Limit]
+		next, err = newProductCursor(products[len(products)-1], opts)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return products, next, nil
+}
+
+// catalogShardReader pages through one shard of a catalog index
+type catalogShardReader struct {
+	client   *dynamodb.Client
+	input    *dynamodb.QueryInput
+	filtered bool
+	buffered []models.Product // read but not yet merged into the listing
+	done     bool
+}
+
+// fill reads the shard's next products once the buffered ones are used up,
+// asking for want items, or for all of them if want is 0
+func (s *catalogShardReader) fill(ctx context.Context, want int) error {
+	// A filtered page can come back empty with more to read
+	for len(s.buffered) == 0 && !s.done {
+		if want > 0 {
+			if s.filtered {
+				// Read ahead, as the filter may reject most items
+				want = max(want, catalogFilterBatch)
+			}
+			s.input.Limit = aws.Int32(int32(want))
+		}
+
+		page, err := s.client.Query(ctx, s.input)
+		if err != nil {
+			return fmt.Errorf("failed to query products: %w", err)
+		}
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &s.buffered); err != nil {
+			return fmt.Errorf("failed to unmarshal products: %w", err)
+		}
+		s.input.ExclusiveStartKey = page.LastEvaluatedKey
+		s.done = page.LastEvaluatedKey == nil
+	}
+	return nil
+}
+
+// Products are spread over catalogShards partitions of the catalog indexes by
+// product ID, so catalog writes do not all land on one index partition. Each
+// shard holds its products in order; List merges them. Changing the shard
+// count moves products between shards, so they must all be rewritten.
+const (
+	catalogAttribute = "catalog"
+	catalogShards    = 4
+)
+
+// catalogPartition returns the catalog index partition key of a shard
+func catalogPartition(shard int) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: fmt.Sprintf("product#%d", shard)}
+}
+
+// catalogFilterBatch is the fewest items read per query of a filtered listing
+const catalogFilterBatch = 100
+
+// catalogIndex is the GSI ordering the catalog by one sort field
+type catalogIndex struct {
+	name      string
+	attribute string // the index's sort key
+	// sortKey returns the sort key of a listing position. Keys of fields that
+	// are not unique end in the product ID, so ties are ordered by it.
+	sortKey func(text string, number, productID int) types.AttributeValue
+}
+
+// catalogIndexes maps each sort field to its index; see terraform/modules/dynamodb
+var catalogIndexes = map[ProductSortField]catalogIndex{
+	ProductSortByID: {"catalog-product_id-index", "product_id", func(_ string, _, productID int) types.AttributeValue {
+		return &types.AttributeValueMemberN{Value: strconv.Itoa(productID)}
+	}},
+	ProductSortBySKU: {"catalog-sku-index", "sku", func(text string, _, _ int) types.AttributeValue {
+		return &types.AttributeValueMemberS{Value: text}
+	}},
+	// The NUL separator sorts below every character, so a name sorts before
+	// longer names it is a prefix of, as it does in MySQL and in memory
+	ProductSortByManufacturer: {"catalog-manufacturer-index", "catalog_manufacturer", func(text string, _, productID int) types.AttributeValue {
+		return &types.AttributeValueMemberS{Value: fmt.Sprintf("%s\x00%010d", text, productID)}
+	}},
+	ProductSortByCategoryID: {"catalog-category_id-index", "catalog_category_id", numberSortKey},
+	ProductSortByWeight:     {"catalog-weight-index", "catalog_weight", numberSortKey},
+}
+
+// numberSortKey orders non-negative numbers as fixed width strings
+func numberSortKey(_ string, number, productID int) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: fmt.Sprintf("%010d#%010d", number, productID)}
+}
+
+// key returns the index key of a listing position within a shard, for
+// ExclusiveStartKey
+func (i catalogIndex) key(shard int, text string, number, productID int) map[string]types.AttributeValue {
+	key := productKey(productID)
+	key[catalogAttribute] = catalogPartition(shard)
+	key[i.attribute] = i.sortKey(text, number, productID)
+	return key
+}
+
+// marshalProduct returns the product's item, including its catalog index keys
+func marshalProduct(product models.Product) (map[string]types.AttributeValue, error) {
+	av, err := attributevalue.MarshalMap(product)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal product: %w", err)
+	}
+	av[catalogAttribute] = catalogPartition(product.ProductID % catalogShards)
+	for field, index := range catalogIndexes {
+		text, number := productSortValue(product, field)
+		av[index.attribute] = index.sortKey(text, number, product.ProductID)
+	}
+	return av, nil
+}
+
+func productKey(id int) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"product_id": &types.AttributeValueMemberN{Value: strconv.Itoa(id)},
+	}
+}
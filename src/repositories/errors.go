@@ -0,0 +1,54 @@
+package repositories
+
+import "errors"
+
+var (
+	// ErrInvalidCartID is returned when a cart ID is not valid for the configured backend
+	ErrInvalidCartID = errors.New("invalid cart ID")
+	// ErrCartNotFound is returned when the requested cart does not exist
+	ErrCartNotFound = errors.New("cart not found")
+	// ErrCartEmpty is returned when checking out a cart without items
+	ErrCartEmpty = errors.New("cart is empty")
+	// ErrCartCheckedOut is returned when modifying or checking out a cart that was already checked out
+	ErrCartCheckedOut = errors.New("cart already checked out")
+	// ErrCartPaid is returned when modifying or paying for a cart that already has an approved payment
+	ErrCartPaid = errors.New("cart already paid")
+	// ErrCartHasPayments is returned when deleting a cart that payments refer to
+	ErrCartHasPayments = errors.New("cart has payments")
+	// ErrConcurrentModification is returned when a cart or product kept changing underneath a write
+	ErrConcurrentModification = errors.New("modified concurrently")
+	// ErrItemNotFound is returned when the product is not in the cart
+	ErrItemNotFound = errors.New("item not found in cart")
+	// ErrProductNotFound is returned when updating or deleting a product that does not exist
+	ErrProductNotFound = errors.New("product not found")
+	// ErrProductExists is returned when creating a product whose ID is already taken
+	ErrProductExists = errors.New("product already exists")
+	// ErrDuplicateSKU is returned when a product's SKU is already used by another product
+	ErrDuplicateSKU = errors.New("SKU already in use")
+	// ErrInvalidCursor is returned when a continuation token cannot be decoded
+	ErrInvalidCursor = errors.New("invalid continuation token")
+	// ErrInsufficientInventory is returned when reserving more than is available
+	ErrInsufficientInventory = errors.New("insufficient inventory")
+	// ErrInsufficientReserved is returned when shipping more than is reserved
+	ErrInsufficientReserved = errors.New("insufficient reserved inventory")
+)
+
+// expectedErrors are outcomes of a correct request against a healthy backend,
+// such as a missing record or a broken business rule
+var expectedErrors = []error{
+	ErrInvalidCartID, ErrCartNotFound, ErrCartEmpty, ErrCartCheckedOut, ErrCartPaid, ErrCartHasPayments, ErrItemNotFound,
+	ErrProductNotFound, ErrProductExists, ErrDuplicateSKU, ErrInvalidCursor,
+	ErrInsufficientInventory, ErrInsufficientReserved,
+}
+
+// IsExpectedError reports whether err is one of the sentinel errors that
+// describe an expected outcome rather than a backend failure. Concurrent
+// modification is not expected: it signals contention in the backend.
+func IsExpectedError(err error) bool {
+	for _, expected := range expectedErrors {
+		if errors.Is(err, expected) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,312 @@
+package repositories
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"store_product/models"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OperationObserver is told the duration and outcome of every call made
+// through an instrumented repository
+type OperationObserver interface {
+	ObserveOperation(repository, operation string, duration time.Duration, err error)
+}
+
+// Instrumentation is where instrumented repositories report their calls:
+// each call gets a trace span, an observation and, if it failed for a reason
+// other than an expected outcome, a log record
+type Instrumentation struct {
+	Backend  string // storage backend, e.g. "mysql"
+	Observer OperationObserver
+	Logger   *slog.Logger
+	Tracer   trace.Tracer
+}
+
+// observedCall is a repository call in progress
+type observedCall struct {
+	inst       Instrumentation
+	ctx        context.Context
+	span       trace.Span
+	repository string
+	operation  string
+	start      time.Time
+}
+
+// start opens the span of a repository call. The returned context carries the
+// span, so database spans nest below it.
+func (i Instrumentation) start(ctx context.Context, repository, operation string) (context.Context, *observedCall) {
+	name := strings.ToUpper(repository[:1]) + repository[1:] + "Repository." + operation
+	ctx, span := i.Tracer.Start(ctx, name, trace.WithAttributes(
+		attribute.String("repository.backend", i.Backend),
+		attribute.String("repository.name", repository),
+		attribute.String("repository.operation", operation),
+	))
+	return ctx, &observedCall{inst: i, ctx: ctx, span: span, repository: repository, operation: operation, start: time.Now()}
+}
+
+// end reports the finished call; err is read when the deferred call runs,
+// after the operation has returned
+func (c *observedCall) end(err *error) {
+	defer c.span.End()
+
+	duration := time.Since(c.start)
+	c.inst.Observer.ObserveOperation(c.repository, c.operation, duration, *err)
+	if *err == nil || IsExpectedError(*err) {
+		return
+	}
+
+	c.span.RecordError(*err)
+	c.span.SetStatus(codes.Error, (*err).Error())
+	c.inst.Logger.WarnContext(c.ctx, "repository operation failed",
+		"repository", c.repository,
+		"operation", c.operation,
+		"duration_ms", float64(duration.Microseconds())/1000,
+		"error", (*err).Error(),
+	)
+}
+
+// InstrumentedCartRepository reports each call of the wrapped cart repository
+// to its Instrumentation
+type InstrumentedCartRepository struct {
+	next CartRepositoryInterface
+	inst Instrumentation
+}
+
+// NewInstrumentedCartRepository wraps next so its calls are traced, observed
+// and their failures logged
+func NewInstrumentedCartRepository(next CartRepositoryInterface, inst Instrumentation) *InstrumentedCartRepository {
+	return &InstrumentedCartRepository{next: next, inst: inst}
+}
+
+// Ensure InstrumentedCartRepository implements CartRepositoryInterface
+var _ CartRepositoryInterface = (*InstrumentedCartRepository)(nil)
+
+// Create creates a new shopping cart
+func (r *InstrumentedCartRepository) Create(ctx context.Context, customerID int) (_ models.CartID, err error) {
+	ctx, call := r.inst.start(ctx, "cart", "Create")
+	defer call.end(&err)
+	return r.next.Create(ctx, customerID)
+}
+
+// GetByID retrieves a shopping cart by ID with all items
+func (r *InstrumentedCartRepository) GetByID(ctx context.Context, cartID models.CartID) (_ *models.ShoppingCart, err error) {
+	ctx, call := r.inst.start(ctx, "cart", "GetByID")
+	defer call.end(&err)
+	return r.next.GetByID(ctx, cartID)
+}
+
+// Exists checks if a cart exists
+func (r *InstrumentedCartRepository) Exists(ctx context.Context, cartID models.CartID) (_ bool, err error) {
+	ctx, call := r.inst.start(ctx, "cart", "Exists")
+	defer call.end(&err)
+	return r.next.Exists(ctx, cartID)
+}
+
+// AddItem adds an item to the cart, or increments its quantity if already present
+func (r *InstrumentedCartRepository) AddItem(ctx context.Context, cartID models.CartID, productID, quantity int, price *models.Money) (err error) {
+	ctx, call := r.inst.start(ctx, "cart", "AddItem")
+	defer call.end(&err)
+	return r.next.AddItem(ctx, cartID, productID, quantity, price)
+}
+
+// UpdateItemQuantity sets the quantity of an item already in the cart
+func (r *InstrumentedCartRepository) UpdateItemQuantity(ctx context.Context, cartID models.CartID, productID, quantity int) (err error) {
+	ctx, call := r.inst.start(ctx, "cart", "UpdateItemQuantity")
+	defer call.end(&err)
+	return r.next.UpdateItemQuantity(ctx, cartID, productID, quantity)
+}
+
+// RemoveItem removes a single item from the cart
+func (r *InstrumentedCartRepository) RemoveItem(ctx context.Context, cartID models.CartID, productID int) (err error) {
+	ctx, call := r.inst.start(ctx, "cart", "RemoveItem")
+	defer call.end(&err)
+	return r.next.RemoveItem(ctx, cartID, productID)
+}
+
+// ClearItems removes all items from the cart
+func (r *InstrumentedCartRepository) ClearItems(ctx context.Context, cartID models.CartID) (err error) {
+	ctx, call := r.inst.start(ctx, "cart", "ClearItems")
+	defer call.end(&err)
+	return r.next.ClearItems(ctx, cartID)
+}
+
+// Delete removes the cart and all of its items
+func (r *InstrumentedCartRepository) Delete(ctx context.Context, cartID models.CartID) (err error) {
+	ctx, call := r.inst.start(ctx, "cart", "Delete")
+	defer call.end(&err)
+	return r.next.Delete(ctx, cartID)
+}
+
+// GetByCustomerID retrieves a page of carts for a customer, newest first
+func (r *InstrumentedCartRepository) GetByCustomerID(ctx context.Context, customerID int, opts CartListOptions) (_ []models.ShoppingCart, _ string, err error) {
+	ctx, call := r.inst.start(ctx, "cart", "GetByCustomerID")
+	defer call.end(&err)
+	return r.next.GetByCustomerID(ctx, customerID, opts)
+}
+
+// Checkout freezes the cart and snapshots its items into a new order
+func (r *InstrumentedCartRepository) Checkout(ctx context.Context, cartID models.CartID) (_ models.OrderID, err error) {
+	ctx, call := r.inst.start(ctx, "cart", "Checkout")
+	defer call.end(&err)
+	return r.next.Checkout(ctx, cartID)
+}
+
+// InstrumentedProductRepository reports each call of the wrapped product
+// repository to its Instrumentation
+type InstrumentedProductRepository struct {
+	next ProductRepositoryInterface
+	inst Instrumentation
+}
+
+// NewInstrumentedProductRepository wraps next so its calls are traced, observed
+// and their failures logged
+func NewInstrumentedProductRepository(next ProductRepositoryInterface, inst Instrumentation) *InstrumentedProductRepository {
+	return &InstrumentedProductRepository{next: next, inst: inst}
+}
+
+// Ensure InstrumentedProductRepository implements ProductRepositoryInterface
+var _ ProductRepositoryInterface = (*InstrumentedProductRepository)(nil)
+
+// GetByID retrieves a product by ID
+func (r *InstrumentedProductRepository) GetByID(ctx context.Context, id int) (_ *models.Product, err error) {
+	ctx, call := r.inst.start(ctx, "product", "GetByID")
+	defer call.end(&err)
+	return r.next.GetByID(ctx, id)
+}
+
+// GetByIDs retrieves several products at once
+func (r *InstrumentedProductRepository) GetByIDs(ctx context.Context, ids []int) (_ []models.Product, err error) {
+	ctx, call := r.inst.start(ctx, "product", "GetByIDs")
+	defer call.end(&err)
+	return r.next.GetByIDs(ctx, ids)
+}
+
+// GetBySKU retrieves a product by SKU
+func (r *InstrumentedProductRepository) GetBySKU(ctx context.Context, sku string) (_ *models.Product, err error) {
+	ctx, call := r.inst.start(ctx, "product", "GetBySKU")
+	defer call.end(&err)
+	return r.next.GetBySKU(ctx, sku)
+}
+
+// Create stores a new product
+func (r *InstrumentedProductRepository) Create(ctx context.Context, product models.Product) (err error) {
+	ctx, call := r.inst.start(ctx, "product", "Create")
+	defer call.end(&err)
+	return r.next.Create(ctx, product)
+}
+
+// Update replaces an existing product
+func (r *InstrumentedProductRepository) Update(ctx context.Context, product models.Product) (err error) {
+	ctx, call := r.inst.start(ctx, "product", "Update")
+	defer call.end(&err)
+	return r.next.Update(ctx, product)
+}
+
+// Delete removes a product
+func (r *InstrumentedProductRepository) Delete(ctx context.Context, id int) (err error) {
+	ctx, call := r.inst.start(ctx, "product", "Delete")
+	defer call.end(&err)
+	return r.next.Delete(ctx, id)
+}
+
+// Exists checks if a product exists
+func (r *InstrumentedProductRepository) Exists(ctx context.Context, id int) (_ bool, err error) {
+	ctx, call := r.inst.start(ctx, "product", "Exists")
+	defer call.end(&err)
+	return r.next.Exists(ctx, id)
+}
+
+// List retrieves a filtered, sorted page of products
+func (r *InstrumentedProductRepository) List(ctx context.Context, opts ProductListOptions) (_ []models.Product, _ string, err error) {
+	ctx, call := r.inst.start(ctx, "product", "List")
+	defer call.end(&err)
+	return r.next.List(ctx, opts)
+}
+
+// InstrumentedInventoryRepository reports each call of the wrapped inventory
+// repository to its Instrumentation
+type InstrumentedInventoryRepository struct {
+	next InventoryRepositoryInterface
+	inst Instrumentation
+}
+
+// NewInstrumentedInventoryRepository wraps next so its calls are traced, observed
+// and their failures logged
+func NewInstrumentedInventoryRepository(next InventoryRepositoryInterface, inst Instrumentation) *InstrumentedInventoryRepository {
+	return &InstrumentedInventoryRepository{next: next, inst: inst}
+}
+
+// Ensure InstrumentedInventoryRepository implements InventoryRepositoryInterface
+var _ InventoryRepositoryInterface = (*InstrumentedInventoryRepository)(nil)
+
+// Get retrieves the inventory of a product, or nil if it has never been stocked
+func (r *InstrumentedInventoryRepository) Get(ctx context.Context, productID int) (_ *models.Inventory, err error) {
+	ctx, call := r.inst.start(ctx, "inventory", "Get")
+	defer call.end(&err)
+	return r.next.Get(ctx, productID)
+}
+
+// Restock adds on-hand stock for a product
+func (r *InstrumentedInventoryRepository) Restock(ctx context.Context, productID, quantity int) (err error) {
+	ctx, call := r.inst.start(ctx, "inventory", "Restock")
+	defer call.end(&err)
+	return r.next.Restock(ctx, productID, quantity)
+}
+
+// Reserve moves available stock into reserved stock
+func (r *InstrumentedInventoryRepository) Reserve(ctx context.Context, productID, quantity int) (err error) {
+	ctx, call := r.inst.start(ctx, "inventory", "Reserve")
+	defer call.end(&err)
+	return r.next.Reserve(ctx, productID, quantity)
+}
+
+// Ship removes reserved stock from the warehouse
+func (r *InstrumentedInventoryRepository) Ship(ctx context.Context, productID, quantity int) (err error) {
+	ctx, call := r.inst.start(ctx, "inventory", "Ship")
+	defer call.end(&err)
+	return r.next.Ship(ctx, productID, quantity)
+}
+
+// InstrumentedPaymentRepository reports each call of the wrapped payment
+// repository to its Instrumentation
+type InstrumentedPaymentRepository struct {
+	next PaymentRepositoryInterface
+	inst Instrumentation
+}
+
+// NewInstrumentedPaymentRepository wraps next so its calls are traced, observed
+// and their failures logged
+func NewInstrumentedPaymentRepository(next PaymentRepositoryInterface, inst Instrumentation) *InstrumentedPaymentRepository {
+	return &InstrumentedPaymentRepository{next: next, inst: inst}
+}
+
+// Ensure InstrumentedPaymentRepository implements PaymentRepositoryInterface
+var _ PaymentRepositoryInterface = (*InstrumentedPaymentRepository)(nil)
+
+// Save records a payment transaction
+func (r *InstrumentedPaymentRepository) Save(ctx context.Context, payment *models.Payment) (err error) {
+	ctx, call := r.inst.start(ctx, "payment", "Save")
+	defer call.end(&err)
+	return r.next.Save(ctx, payment)
+}
+
+// GetByTransactionID retrieves a payment by transaction ID
+func (r *InstrumentedPaymentRepository) GetByTransactionID(ctx context.Context, transactionID string) (_ *models.Payment, err error) {
+	ctx, call := r.inst.start(ctx, "payment", "GetByTransactionID")
+	defer call.end(&err)
+	return r.next.GetByTransactionID(ctx, transactionID)
+}
+
+// GetApprovedByCartID retrieves the approved payment for a cart
+func (r *InstrumentedPaymentRepository) GetApprovedByCartID(ctx context.Context, cartID models.CartID) (_ *models.Payment, err error) {
+	ctx, call := r.inst.start(ctx, "payment", "GetApprovedByCartID")
+	defer call.end(&err)
+	return r.next.GetApprovedByCartID(ctx, cartID)
+}
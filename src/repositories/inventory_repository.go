@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"store_product/models"
+)
+
+// MySQLInventoryRepository handles warehouse inventory operations for MySQL
+type MySQLInventoryRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLInventoryRepository creates a new MySQL inventory repository
+func NewMySQLInventoryRepository(db *sql.DB) *MySQLInventoryRepository {
+	return &MySQLInventoryRepository{db: db}
+}
+
+// Ensure MySQLInventoryRepository implements InventoryRepositoryInterface
+var _ InventoryRepositoryInterface = (*MySQLInventoryRepository)(nil)
+
+// Get retrieves the inventory of a product, or nil if it has never been stocked
+func (r *MySQLInventoryRepository) Get(ctx context.Context, productID int) (*models.Inventory, error) {
+	var inv models.Inventory
+	err := r.db.QueryRowContext(ctx,
+		"SELECT product_id, on_hand, reserved, updated_at FROM inventory WHERE product_id = ?",
+		productID,
+	).Scan(&inv.ProductID, &inv.OnHand, &inv.Reserved, &inv.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch inventory: %w", err)
+	}
+	inv.Available = inv.OnHand - inv.Reserved
+	return &inv, nil
+}
+
+// Restock adds on-hand stock for a product
+func (r *MySQLInventoryRepository) Restock(ctx context.Context, productID, quantity int) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO inventory (product_id, on_hand)
+		VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE
+			on_hand = on_hand + VALUES(on_hand),
+			updated_at = CURRENT_TIMESTAMP
+	`, productID, quantity)
+	if err != nil {
+		return fmt.Errorf("failed to restock inventory: %w", err)
+	}
+	return nil
+}
+
+// Reserve moves available stock into reserved stock.
+// The availability check and the increment happen in a single conditional UPDATE.
+func (r *MySQLInventoryRepository) Reserve(ctx context.Context, productID, quantity int) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE inventory
+		SET reserved = reserved + ?, updated_at = CURRENT_TIMESTAMP
+		WHERE product_id = ? AND on_hand - reserved >= ?
+	`, quantity, productID, quantity)
+	if err != nil {
+		return fmt.Errorf("failed to reserve inventory: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to reserve inventory: %w", err)
+	}
+	if affected == 0 {
+		return ErrInsufficientInventory
+	}
+	return nil
+}
+
+// Ship removes reserved stock from the warehouse
+func (r *MySQLInventoryRepository) Ship(ctx context.Context, productID, quantity int) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE inventory
+		SET reserved = reserved - ?, on_hand = on_hand - ?, updated_at = CURRENT_TIMESTAMP
+		WHERE product_id = ? AND reserved >= ?
+	`, quantity, quantity, productID, quantity)
+	if err != nil {
+		return fmt.Errorf("failed to ship inventory: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to ship inventory: %w", err)
+	}
+	if affected == 0 {
+		return ErrInsufficientReserved
+	}
+	return nil
+}
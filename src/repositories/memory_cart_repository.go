@@ -0,0 +1,398 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"store_product/models"
+)
+
+// InMemoryCartRepository handles shopping cart data operations in process memory.
+// It mirrors the other backends: adding a product already in the cart
+// increments its quantity, paid and checked-out carts are frozen and carts
+// expire a configured TTL after creation, as on DynamoDB.
+type InMemoryCartRepository struct {
+	mu          sync.Mutex
+	carts       map[int]*models.ShoppingCart
+	orders      map[int]models.Order
+	nextCartID  int
+	nextOrderID int
+	ttl         time.Duration
+	now         func() time.Time
+}
+
+// NewInMemoryCartRepository creates a new in-memory cart repository whose
+// carts expire ttl after creation
+func NewInMemoryCartRepository(ttl time.Duration) *InMemoryCartRepository {
+	return &InMemoryCartRepository{
+		carts:  make(map[int]*models.ShoppingCart),
+		orders: make(map[int]models.Order),
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// Ensure InMemoryCartRepository implements CartRepositoryInterface
+var _ CartRepositoryInterface = (*InMemoryCartRepository)(nil)
+
+// Create creates a new shopping cart
+func (r *InMemoryCartRepository) Create(ctx context.Context, customerID int) (models.CartID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextCartID++
+	now := r.now()
+	ttl := now.Add(r.ttl).Unix()
+
+	r.carts[r.nextCartID] = &models.ShoppingCart{
+		CartID:     memoryToCartID(r.nextCartID),
+		CustomerID: customerID,
+		Status:     models.CartStatusActive,
+		Version:    1,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Items:      []models.CartItem{},
+		TTL:        &ttl,
+	}
+	return memoryToCartID(r.nextCartID), nil
+}
+
+// GetByID retrieves a shopping cart by ID with all items
+func (r *InMemoryCartRepository) GetByID(ctx context.Context, cartID models.CartID) (*models.ShoppingCart, error) {
+	id, err := memoryCartID(cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cart := r.lookup(id)
+	if cart == nil {
+		return nil, nil
+	}
+	return copyCart(cart), nil
+}
+
+// Exists checks if a cart exists
+func (r *InMemoryCartRepository) Exists(ctx context.Context, cartID models.CartID) (bool, error) {
+	cart, err := r.GetByID(ctx, cartID)
+	if err != nil {
+		return false, err
+	}
+	return cart != nil, nil
+}
+
+// AddItem adds an item to the cart, or increments its quantity if already present
+func (r *InMemoryCartRepository) AddItem(ctx context.Context, cartID models.CartID, productID, quantity int, price *models.Money) error {
+	return r.modifyItems(cartID, func(cart *models.ShoppingCart, now time.Time) error {
+		for i, item := range cart.Items {
+			if item.ProductID == productID {
+				cart.Items[i].Quantity += quantity
+				cart.Items[i].UpdatedAt = now
+				return nil
+			}
+		}
+
+		// Keep a copy so the caller's value cannot change the snapshot
+		var snapshot *models.Money
+		if price != nil {
+			p := *price
+			snapshot = &p
+		}
+		cart.Items = append(cart.Items, models.CartItem{
+			ItemID:     cart.NextItemID(),
+			ProductID:  productID,
+			Quantity:   quantity,
+			PriceAtAdd: snapshot,
+			AddedAt:    now,
+			UpdatedAt:  now,
+		})
+		return nil
+	})
+}
+
+// UpdateItemQuantity sets the quantity of an item already in the cart
+func (r *InMemoryCartRepository) UpdateItemQuantity(ctx context.Context, cartID models.CartID, productID, quantity int) error {
+	return r.modifyItems(cartID, func(cart *models.ShoppingCart, now time.Time) error {
+		for i, item := range cart.Items {
+			if item.ProductID == productID {
+				cart.Items[i].Quantity = quantity
+				cart.Items[i].UpdatedAt = now
+				return nil
+			}
+		}
+		return ErrItemNotFound
+	})
+}
+
+// RemoveItem removes a single item from the cart
+func (r *InMemoryCartRepository) RemoveItem(ctx context.Context, cartID models.CartID, productID int) error {
+	return r.modifyItems(cartID, func(cart *models.ShoppingCart, now time.Time) error {
+		for i, item := range cart.Items {
+			if item.ProductID == productID {
+				cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+				return nil
+			}
+		}
+		return ErrItemNotFound
+	})
+}
+
+// ClearItems removes all items from the cart
+func (r *InMemoryCartRepository) ClearItems(ctx context.Context, cartID models.CartID) error {
+	return r.modifyItems(cartID, func(cart *models.ShoppingCart, now time.Time) error {
+		cart.Items = []models.CartItem{}
+		return nil
+	})
+}
+
+// Delete removes the cart and all of its items
+func (r *InMemoryCartRepository) Delete(ctx context.Context, cartID models.CartID) error {
+	id, err := memoryCartID(cartID)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cart := r.lookup(id)
+	if cart == nil {
+		return ErrCartNotFound
+	}
+	// Checked-out carts are referenced by their order and must be kept
+	if cart.IsCheckedOut() {
+		return ErrCartCheckedOut
+	}
+	if cart.PaymentAttempts > 0 {
+		return ErrCartHasPayments
+	}
+	delete(r.carts, id)
+	return nil
+}
+
+// modifyItems lets fn change the items of an active, unpaid cart under the repository
+// lock, so concurrent writers to the same cart are serialized
+func (r *InMemoryCartRepository) modifyItems(cartID models.CartID, fn func(cart *models.ShoppingCart, now time.Time) error) error {
+	id, err := memoryCartID(cartID)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cart := r.lookup(id)
+	if cart == nil {
+		return ErrCartNotFound
+	}
+	if cart.IsCheckedOut() {
+		return ErrCartCheckedOut
+	}
+	if cart.IsPaid() {
+		return ErrCartPaid
+	}
+
+	// Work on a copy so a failing fn leaves the stored cart untouched
+	updated := copyCart(cart)
+	now := r.now()
+	if err := fn(updated, now); err != nil {
+		return err
+	}
+	updated.UpdatedAt = now
+	updated.Version++
+	r.carts[id] = updated
+	return nil
+}
+
+// memoryCartCursor is the keyset position of the last cart on a page
+type memoryCartCursor struct {
+	CreatedAt time.Time `json:"c"`
+	CartID    int       `json:"i"`
+}
+
+// GetByCustomerID retrieves a page of carts for a customer, newest first
+func (r *InMemoryCartRepository) GetByCustomerID(ctx context.Context, customerID int, opts CartListOptions) ([]models.ShoppingCart, string, error) {
+	var after *memoryCartCursor
+	if opts.Cursor != "" {
+		after = &memoryCartCursor{}
+		if err := decodeCursor(opts.Cursor, after); err != nil {
+			return nil, "", err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	type entry struct {
+		id   int
+		cart *models.ShoppingCart
+	}
+	var matches []entry
+	for id := range r.carts {
+		cart := r.lookup(id)
+		if cart == nil || cart.CustomerID != customerID {
+			continue
+		}
+		if after != nil && !cart.CreatedAt.Before(after.CreatedAt) &&
+			!(cart.CreatedAt.Equal(after.CreatedAt) && id < after.CartID) {
+			continue
+		}
+		matches = append(matches, entry{id: id, cart: cart})
+	}
+
+	// Same ordering as the MySQL keyset: (created_at DESC, cart_id DESC)
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].cart.CreatedAt.Equal(matches[j].cart.CreatedAt) {
+			return matches[i].cart.CreatedAt.After(matches[j].cart.CreatedAt)
+		}
+		return matches[i].id > matches[j].id
+	})
+
+	var next string
+	if opts.Limit > 0 && len(matches) > opts.Limit {
+		matches = matches[:opts.Limit]
+		last := matches[len(matches)-1]
+		var err error
+		next, err = encodeCursor(memoryCartCursor{CreatedAt: last.cart.CreatedAt, CartID: last.id})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+
+	carts := make([]models.ShoppingCart, 0, len(matches))
+	for _, m := range matches {
+		cart := copyCart(m.cart)
+		if !opts.IncludeItems {
+			cart.Items = nil
+		}
+		carts = append(carts, *cart)
+	}
+	return carts, next, nil
+}
+
+// Checkout freezes the cart and snapshots its items into a new order
+func (r *InMemoryCartRepository) Checkout(ctx context.Context, cartID models.CartID) (models.OrderID, error) {
+	id, err := memoryCartID(cartID)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cart := r.lookup(id)
+	if cart == nil {
+		return "", ErrCartNotFound
+	}
+	if cart.IsCheckedOut() {
+		return "", ErrCartCheckedOut
+	}
+
+	items := make([]models.OrderItem, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		if item.Quantity > 0 {
+			items = append(items, models.OrderItem{ProductID: item.ProductID, Quantity: item.Quantity})
+		}
+	}
+	if len(items) == 0 {
+		return "", ErrCartEmpty
+	}
+
+	now := r.now()
+	r.nextOrderID++
+	orderID := models.OrderID(strconv.Itoa(r.nextOrderID))
+	r.orders[r.nextOrderID] = models.Order{
+		OrderID:    orderID,
+		CartID:     cart.CartID,
+		CustomerID: cart.CustomerID,
+		Items:      items,
+		CreatedAt:  now,
+	}
+
+	updated := copyCart(cart)
+	updated.Status = models.CartStatusCheckedOut
+	updated.UpdatedAt = now
+	updated.Version++
+	r.carts[id] = updated
+
+	return orderID, nil
+}
+
+// recordPayment checks that a payment can be recorded for the cart, counts
+// it, and for an approved payment marks the cart paid. InMemoryPaymentRepository calls it
+// while holding its own lock, so both changes appear together.
+func (r *InMemoryCartRepository) recordPayment(cartID models.CartID, approved bool) error {
+	id, err := memoryCartID(cartID)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cart := r.lookup(id)
+	if cart == nil {
+		return ErrCartNotFound
+	}
+	if cart.IsCheckedOut() {
+		return ErrCartCheckedOut
+	}
+	if approved && cart.IsPaid() {
+		return ErrCartPaid
+	}
+
+	updated := copyCart(cart)
+	updated.PaymentAttempts++
+	if approved {
+		updated.Status = models.CartStatusPaid
+		updated.UpdatedAt = r.now()
+		updated.Version++
+	}
+	r.carts[id] = updated
+	return nil
+}
+
+// lookup returns the stored cart, dropping it if its TTL has passed the way
+// DynamoDB would; callers must hold r.mu
+func (r *InMemoryCartRepository) lookup(id int) *models.ShoppingCart {
+	cart, ok := r.carts[id]
+	if !ok {
+		return nil
+	}
+	if cart.TTL != nil && r.now().Unix() >= *cart.TTL {
+		delete(r.carts, id)
+		return nil
+	}
+	return cart
+}
+
+// copyCart returns a deep copy so callers never share item slices with the store
+func copyCart(cart *models.ShoppingCart) *models.ShoppingCart {
+	c := *cart
+	c.Items = append([]models.CartItem{}, cart.Items...)
+	if cart.TTL != nil {
+		ttl := *cart.TTL
+		c.TTL = &ttl
+	}
+	return &c
+}
+
+// memoryCartID decodes a CartID into the sequential integer key used in memory
+func memoryCartID(cartID models.CartID) (int, error) {
+	id, err := strconv.Atoi(string(cartID))
+	if err != nil || id < 1 {
+		return 0, ErrInvalidCartID
+	}
+	return id, nil
+}
+
+// memoryToCartID encodes a sequential integer key as a CartID
+func memoryToCartID(id int) models.CartID {
+	return models.CartID(strconv.Itoa(id))
+}
@@ -0,0 +1,18 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"store_product/repositories"
+	"store_product/repositories/carttest"
+)
+
+func TestInMemoryCartRepository(t *testing.T) {
+	repo := repositories.NewInMemoryCartRepository(time.Hour)
+	payments := repositories.NewInMemoryPaymentRepository(repo)
+	if err := carttest.TestRepository(context.Background(), repo, payments); err != nil {
+		t.Fatal(err)
+	}
+}
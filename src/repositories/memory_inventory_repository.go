@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"store_product/models"
+	"sync"
+	"time"
+)
+
+// InMemoryInventoryRepository handles warehouse inventory operations in process memory
+type InMemoryInventoryRepository struct {
+	mu    sync.Mutex
+	stock map[int]models.Inventory
+}
+
+// NewInMemoryInventoryRepository creates a new in-memory inventory repository
+func NewInMemoryInventoryRepository() *InMemoryInventoryRepository {
+	return &InMemoryInventoryRepository{stock: make(map[int]models.Inventory)}
+}
+
+// Ensure InMemoryInventoryRepository implements InventoryRepositoryInterface
+var _ InventoryRepositoryInterface = (*InMemoryInventoryRepository)(nil)
+
+// Get retrieves the inventory of a product, or nil if it has never been stocked
+func (r *InMemoryInventoryRepository) Get(ctx context.Context, productID int) (*models.Inventory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inv, ok := r.stock[productID]
+	if !ok {
+		return nil, nil
+	}
+	return &inv, nil
+}
+
+// Restock adds on-hand stock for a product
+func (r *InMemoryInventoryRepository) Restock(ctx context.Context, productID, quantity int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inv := r.stock[productID]
+	inv.ProductID = productID
+	inv.OnHand += quantity
+	r.save(inv)
+	return nil
+}
+
+// Reserve moves available stock into reserved stock
+func (r *InMemoryInventoryRepository) Reserve(ctx context.Context, productID, quantity int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inv, ok := r.stock[productID]
+	if !ok || inv.OnHand-inv.Reserved < quantity {
+		return ErrInsufficientInventory
+	}
+	inv.Reserved += quantity
+	r.save(inv)
+	return nil
+}
+
+// Ship removes reserved stock from the warehouse
+func (r *InMemoryInventoryRepository) Ship(ctx context.Context, productID, quantity int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inv, ok := r.stock[productID]
+	if !ok || inv.Reserved < quantity {
+		return ErrInsufficientReserved
+	}
+	inv.Reserved -= quantity
+	inv.OnHand -= quantity
+	r.save(inv)
+	return nil
+}
+
+// save stores the inventory with derived fields refreshed; callers must hold r.mu
+func (r *InMemoryInventoryRepository) save(inv models.Inventory) {
+	inv.Available = inv.OnHand - inv.Reserved
+	inv.UpdatedAt = time.Now()
+	r.stock[inv.ProductID] = inv
+}
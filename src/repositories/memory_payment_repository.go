@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"store_product/models"
+	"sync"
+)
+
+// InMemoryPaymentRepository handles payment transaction storage in process
+// memory, for the carts of an InMemoryCartRepository
+type InMemoryPaymentRepository struct {
+	mu       sync.Mutex
+	payments map[string]models.Payment
+	carts    *InMemoryCartRepository
+}
+
+// NewInMemoryPaymentRepository creates a new in-memory payment repository
+// whose payments update the carts in carts
+func NewInMemoryPaymentRepository(carts *InMemoryCartRepository) *InMemoryPaymentRepository {
+	return &InMemoryPaymentRepository{payments: make(map[string]models.Payment), carts: carts}
+}
+
+// Ensure InMemoryPaymentRepository implements PaymentRepositoryInterface
+var _ PaymentRepositoryInterface = (*InMemoryPaymentRepository)(nil)
+
+// Save records a payment transaction
+func (r *InMemoryPaymentRepository) Save(ctx context.Context, payment *models.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.payments[payment.TransactionID]; ok {
+		return fmt.Errorf("failed to save payment: transaction %s already exists", payment.TransactionID)
+	}
+	if err := r.carts.recordPayment(payment.CartID, payment.Status == models.PaymentStatusApproved); err != nil {
+		return err
+	}
+	r.payments[payment.TransactionID] = *payment
+	return nil
+}
+
+// GetByTransactionID retrieves a payment by transaction ID
+func (r *InMemoryPaymentRepository) GetByTransactionID(ctx context.Context, transactionID string) (*models.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment, ok := r.payments[transactionID]
+	if !ok {
+		return nil, nil
+	}
+	return &payment, nil
+}
+
+// GetApprovedByCartID retrieves the first approved payment for a cart
+func (r *InMemoryPaymentRepository) GetApprovedByCartID(ctx context.Context, cartID models.CartID) (*models.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var first *models.Payment
+	for _, payment := range r.payments {
+		if payment.CartID != cartID || payment.Status != models.PaymentStatusApproved {
+			continue
+		}
+		if first == nil || payment.CreatedAt.Before(first.CreatedAt) {
+			p := payment
+			first = &p
+		}
+	}
+	return first, nil
+}
@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"store_product/models"
+	"sync"
+)
+
+// InMemoryProductRepository handles product data operations in process memory
+type InMemoryProductRepository struct {
+	mu       sync.RWMutex
+	products map[int]models.Product
+	skus     map[string]int // SKU -> product ID
+}
+
+// NewInMemoryProductRepository creates a new in-memory product repository
+func NewInMemoryProductRepository() *InMemoryProductRepository {
+	return &InMemoryProductRepository{
+		products: make(map[int]models.Product),
+		skus:     make(map[string]int),
+	}
+}
+
+// Ensure InMemoryProductRepository implements ProductRepositoryInterface
+var _ ProductRepositoryInterface = (*InMemoryProductRepository)(nil)
+
+// GetByID retrieves a product by ID
+func (r *InMemoryProductRepository) GetByID(ctx context.Context, id int) (*models.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return nil, nil
+	}
+	return &product, nil
+}
+
+// GetByIDs retrieves several products at once
+func (r *InMemoryProductRepository) GetByIDs(ctx context.Context, ids []int) ([]models.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	products := []models.Product{}
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		product, ok := r.products[id]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		products = append(products, product)
+	}
+	return products, nil
+}
+
+// GetBySKU retrieves a product by SKU
+func (r *InMemoryProductRepository) GetBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.skus[sku]
+	if !ok {
+		return nil, nil
+	}
+	product := r.products[id]
+	return &product, nil
+}
+
+// Create stores a new product
+func (r *InMemoryProductRepository) Create(ctx context.Context, product models.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.products[product.ProductID]; ok {
+		return ErrProductExists
+	}
+	if _, ok := r.skus[product.SKU]; ok {
+		return ErrDuplicateSKU
+	}
+	r.products[product.ProductID] = product
+	r.skus[product.SKU] = product.ProductID
+	return nil
+}
+
+// Update replaces an existing product
+func (r *InMemoryProductRepository) Update(ctx context.Context, product models.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.products[product.ProductID]
+	if !ok {
+		return ErrProductNotFound
+	}
+	if owner, ok := r.skus[product.SKU]; ok && owner != product.ProductID {
+		return ErrDuplicateSKU
+	}
+	delete(r.skus, current.SKU)
+	r.products[product.ProductID] = product
+	r.skus[product.SKU] = product.ProductID
+	return nil
+}
+
+// Delete removes a product
+func (r *InMemoryProductRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return ErrProductNotFound
+	}
+	delete(r.products, id)
+	delete(r.skus, product.SKU)
+	return nil
+}
+
+// Exists checks if a product exists
+func (r *InMemoryProductRepository) Exists(ctx context.Context, id int) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.products[id]
+	return ok, nil
+}
+
+// List retrieves a filtered, sorted page of products
+func (r *InMemoryProductRepository) List(ctx context.Context, opts ProductListOptions) ([]models.Product, string, error) {
+	r.mu.RLock()
+	products := []models.Product{}
+	for _, product := range r.products {
+		if matchesProductFilter(product, opts.Filter) {
+			products = append(products, product)
+		}
+	}
+	r.mu.RUnlock()
+
+	return pageProducts(products, opts)
+}
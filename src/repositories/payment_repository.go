@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"store_product/models"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQLPaymentRepository handles payment transaction storage for MySQL
+type MySQLPaymentRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLPaymentRepository creates a new MySQL payment repository
+func NewMySQLPaymentRepository(db *sql.DB) *MySQLPaymentRepository {
+	return &MySQLPaymentRepository{db: db}
+}
+
+// Ensure MySQLPaymentRepository implements PaymentRepositoryInterface
+var _ PaymentRepositoryInterface = (*MySQLPaymentRepository)(nil)
+
+// Save records a payment transaction. The cart row stays locked until the
+// payment is written, and an approved payment marks the cart paid in the same
+// transaction; the unique key on approved_cart_id backs up the status check.
+func (r *MySQLPaymentRepository) Save(ctx context.Context, payment *models.Payment) error {
+	cartID, err := mysqlCartID(payment.CartID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, status, err := lockCart(ctx, tx, cartID)
+	if err != nil {
+		return err
+	}
+	approved := payment.Status == models.PaymentStatusApproved
+	if approved && status == models.CartStatusPaid {
+		return ErrCartPaid
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO payments (transaction_id, cart_id, card_last_four, status, decline_reason, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, payment.TransactionID, cartID, payment.CardLastFour, payment.Status, payment.DeclineReason, payment.CreatedAt)
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry && strings.Contains(mysqlErr.Message, "uq_payments_approved_cart") {
+		return ErrCartPaid
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save payment: %w", err)
+	}
+
+	if approved {
+		_, err = tx.ExecContext(ctx,
+			"UPDATE shopping_carts SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE cart_id = ?",
+			models.CartStatusPaid, cartID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to mark cart paid: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit payment: %w", err)
+	}
+	return nil
+}
+
+// GetByTransactionID retrieves a payment by transaction ID
+func (r *MySQLPaymentRepository) GetByTransactionID(ctx context.Context, transactionID string) (*models.Payment, error) {
+	var payment models.Payment
+	var cartID int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT transaction_id, cart_id, card_last_four, status, decline_reason, created_at
+		FROM payments WHERE transaction_id = ?
+	`, transactionID).Scan(
+		&payment.TransactionID, &cartID, &payment.CardLastFour,
+		&payment.Status, &payment.DeclineReason, &payment.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch payment: %w", err)
+	}
+	payment.CartID = mysqlToCartID(cartID)
+	return &payment, nil
+}
+
+// GetApprovedByCartID retrieves the first approved payment for a cart
+func (r *MySQLPaymentRepository) GetApprovedByCartID(ctx context.Context, cartID models.CartID) (*models.Payment, error) {
+	id, err := mysqlCartID(cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	payment := models.Payment{CartID: cartID}
+	err = r.db.QueryRowContext(ctx, `
+		SELECT transaction_id, card_last_four, status, decline_reason, created_at
+		FROM payments WHERE cart_id = ? AND status = ?
+		ORDER BY created_at LIMIT 1
+	`, id, models.PaymentStatusApproved).Scan(
+		&payment.TransactionID, &payment.CardLastFour,
+		&payment.Status, &payment.DeclineReason, &payment.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch payment for cart: %w", err)
+	}
+	return &payment, nil
+}
@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"cmp"
+	"sort"
+	"strings"
+
+	"store_product/models"
+)
+
+// ProductSortField names a product attribute the catalog can be sorted by
+type ProductSortField string
+
+// Product sort fields accepted by ProductRepositoryInterface.List
+const (
+	ProductSortByID           ProductSortField = "product_id"
+	ProductSortBySKU          ProductSortField = "sku"
+	ProductSortByManufacturer ProductSortField = "manufacturer"
+	ProductSortByCategoryID   ProductSortField = "category_id"
+	ProductSortByWeight       ProductSortField = "weight"
+)
+
+// Valid reports whether f is one of the supported sort fields
+func (f ProductSortField) Valid() bool {
+	switch f {
+	case ProductSortByID, ProductSortBySKU, ProductSortByManufacturer, ProductSortByCategoryID, ProductSortByWeight:
+		return true
+	}
+	return false
+}
+
+// ProductFilter narrows a product listing; zero values match everything
+type ProductFilter struct {
+	Manufacturer string // exact manufacturer name
+	CategoryID   int
+	MinWeight    *int // inclusive
+	MaxWeight    *int // inclusive
+	SKUPrefix    string
+}
+
+// ProductListOptions controls filtering, ordering and paging of the catalog.
+// Products with equal sort values are ordered by product ID, so pages are stable.
+type ProductListOptions struct {
+	Filter     ProductFilter
+	SortBy     ProductSortField // defaults to ProductSortByID
+	Descending bool
+	Limit      int    // maximum number of products to return
+	Cursor     string // opaque continuation token from a previous page
+}
+
+// sortBy returns the effective sort field
+func (o ProductListOptions) sortBy() ProductSortField {
+	if o.SortBy == "" {
+		return ProductSortByID
+	}
+	return o.SortBy
+}
+
+// productCursor is the position of the last product on a page. It records the
+// ordering it was issued for so it cannot be replayed against another one.
+type productCursor struct {
+	SortBy     ProductSortField `json:"s"`
+	Descending bool             `json:"d,omitempty"`
+	Text       string           `json:"t,omitempty"` // sort value of string fields
+	Number     int              `json:"n,omitempty"` // sort value of integer fields
+	ProductID  int              `json:"i"`
+}
+
+// newProductCursor encodes the position of p within the listing described by opts
+func newProductCursor(p models.Product, opts ProductListOptions) (string, error) {
+	cursor := productCursor{SortBy: opts.sortBy(), Descending: opts.Descending, ProductID: p.ProductID}
+	cursor.Text, cursor.Number = productSortValue(p, cursor.SortBy)
+	return encodeCursor(cursor)
+}
+
+// parseProductCursor decodes opts.Cursor, rejecting cursors from another ordering
+func parseProductCursor(opts ProductListOptions) (*productCursor, error) {
+	if opts.Cursor == "" {
+		return nil, nil
+	}
+	var cursor productCursor
+	if err := decodeCursor(opts.Cursor, &cursor); err != nil {
+		return nil, err
+	}
+	if cursor.SortBy != opts.sortBy() || cursor.Descending != opts.Descending {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// sortValue returns the cursor's position in the sort field's own type
+func (c *productCursor) sortValue() interface{} {
+	switch c.SortBy {
+	case ProductSortBySKU, ProductSortByManufacturer:
+		return c.Text
+	}
+	return c.Number
+}
+
+// productSortValue returns the value p is ordered by, as text or as a number
+func productSortValue(p models.Product, field ProductSortField) (string, int) {
+	switch field {
+	case ProductSortBySKU:
+		return p.SKU, 0
+	case ProductSortByManufacturer:
+		return p.Manufacturer, 0
+	case ProductSortByCategoryID:
+		return "", p.CategoryID
+	case ProductSortByWeight:
+		return "", productWeight(p)
+	default:
+		return "", p.ProductID
+	}
+}
+
+// productWeight returns p's weight; stored products always have one
+func productWeight(p models.Product) int {
+	if p.Weight == nil {
+		return 0
+	}
+	return *p.Weight
+}
+
+// compareProductPosition orders a product against a position in the listing
+func compareProductPosition(p models.Product, text string, number, productID int, opts ProductListOptions) int {
+	pText, pNumber := productSortValue(p, opts.sortBy())
+	c := cmp.Compare(pText, text)
+	if c == 0 {
+		c = cmp.Compare(pNumber, number)
+	}
+	if c == 0 {
+		c = cmp.Compare(p.ProductID, productID)
+	}
+	if opts.Descending {
+		return -c
+	}
+	return c
+}
+
+// compareProducts orders two products as the listing described by opts does
+func compareProducts(a, b models.Product, opts ProductListOptions) int {
+	text, number := productSortValue(b, opts.sortBy())
+	return compareProductPosition(a, text, number, b.ProductID, opts)
+}
+
+// matchesProductFilter reports whether p passes every set filter
+func matchesProductFilter(p models.Product, f ProductFilter) bool {
+	switch {
+	case f.Manufacturer != "" && p.Manufacturer != f.Manufacturer:
+		return false
+	case f.CategoryID != 0 && p.CategoryID != f.CategoryID:
+		return false
+	case f.MinWeight != nil && productWeight(p) < *f.MinWeight:
+		return false
+	case f.MaxWeight != nil && productWeight(p) > *f.MaxWeight:
+		return false
+	case f.SKUPrefix != "" && !strings.HasPrefix(p.SKU, f.SKUPrefix):
+		return false
+	}
+	return true
+}
+
+// pageProducts sorts already filtered products and cuts out the page
+// following opts.Cursor, for backends that cannot order server-side
+func pageProducts(products []models.Product, opts ProductListOptions) ([]models.Product, string, error) {
+	cursor, err := parseProductCursor(opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	sort.Slice(products, func(i, j int) bool {
+		text, number := productSortValue(products[j], opts.sortBy())
+		return compareProductPosition(products[i], text, number, products[j].ProductID, opts) < 0
+	})
+
+	start := 0
+	if cursor != nil {
+		start = sort.Search(len(products), func(i int) bool {
+			return compareProductPosition(products[i], cursor.Text, cursor.Number, cursor.ProductID, opts) > 0
+		})
+	}
+
+	page := products[start:]
+	var next string
+	if opts.Limit > 0 && len(page) > opts.Limit {
+		page = page[:opts.Limit]
+		next, err = newProductCursor(page[len(page)-1], opts)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return append([]models.Product{}, page...), next, nil
+}
@@ -0,0 +1,101 @@
+package repositories_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"store_product/models"
+	"store_product/repositories"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// orderedManufacturers are the manufacturers of the products created by
+// testProductListOrder, by offset from the first product ID. The names share
+// prefixes, where byte order, collations and composite sort keys tend to
+// disagree; ties are broken by product ID.
+var orderedManufacturers = []string{"Acme Corp", "Acme", "AcmeCo", "Acme", "Acm", "Acme Corp"}
+
+// wantManufacturerOrder is the offsets listed by ascending manufacturer
+var wantManufacturerOrder = []int{4, 1, 3, 0, 5, 2}
+
+// testProductListOrder checks that repo lists products by manufacturer in the
+// order every backend agrees on, page by page. Its products have a unique SKU
+// prefix, so it can run against a database holding other products.
+func testProductListOrder(t *testing.T, repo repositories.ProductRepositoryInterface) {
+	ctx := context.Background()
+	now := time.Now().UnixNano()
+	firstID := int(now%1_000_000_000) + 1
+	prefix := fmt.Sprintf("ORDER-%d-", now)
+
+	weight := 100
+	for offset, manufacturer := range orderedManufacturers {
+		product := models.Product{
+			ProductID:    firstID + offset,
+			SKU:          fmt.Sprintf("%s%d", prefix, offset),
+			Manufacturer: manufacturer,
+			CategoryID:   1,
+			Weight:       &weight,
+			SomeOtherID:  1,
+		}
+		if err := repo.Create(ctx, product); err != nil {
+			t.Fatalf("Create(%d): %v", product.ProductID, err)
+		}
+		t.Cleanup(func() { repo.Delete(context.Background(), product.ProductID) })
+	}
+
+	for _, descending := range []bool{false, true} {
+		want := slices.Clone(wantManufacturerOrder)
+		if descending {
+			slices.Reverse(want)
+		}
+
+		opts := repositories.ProductListOptions{
+			Filter:     repositories.ProductFilter{SKUPrefix: prefix},
+			SortBy:     repositories.ProductSortByManufacturer,
+			Descending: descending,
+			Limit:      2,
+		}
+		var got []int
+		for {
+			products, next, err := repo.List(ctx, opts)
+			if err != nil {
+				t.Fatalf("List(descending=%v): %v", descending, err)
+			}
+			for _, p := range products {
+				got = append(got, p.ProductID-firstID)
+			}
+			if next == "" {
+				break
+			}
+			opts.Cursor = next
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("listing by manufacturer (descending=%v) gave offsets %v, want %v", descending, got, want)
+		}
+	}
+}
+
+func TestInMemoryProductListOrder(t *testing.T) {
+	testProductListOrder(t, repositories.NewInMemoryProductRepository())
+}
+
+func TestMySQLProductListOrder(t *testing.T) {
+	testProductListOrder(t, repositories.NewMySQLProductRepository(newMySQLTestDB(t)))
+}
+
+func TestDynamoDBProductListOrder(t *testing.T) {
+	client := newDynamoDBLocalClient(t)
+	catalog := attribute{"catalog", types.ScalarAttributeTypeS}
+	products := createTable(t, client, "products", attribute{"product_id", types.ScalarAttributeTypeN},
+		index{name: "catalog-product_id-index", hashKey: catalog, rangeKey: &attribute{"product_id", types.ScalarAttributeTypeN}},
+		index{name: "catalog-sku-index", hashKey: catalog, rangeKey: &attribute{"sku", types.ScalarAttributeTypeS}},
+		index{name: "catalog-manufacturer-index", hashKey: catalog, rangeKey: &attribute{"catalog_manufacturer", types.ScalarAttributeTypeS}},
+		index{name: "catalog-category_id-index", hashKey: catalog, rangeKey: &attribute{"catalog_category_id", types.ScalarAttributeTypeS}},
+		index{name: "catalog-weight-index", hashKey: catalog, rangeKey: &attribute{"catalog_weight", types.ScalarAttributeTypeS}})
+	skus := createTable(t, client, "product-skus", attribute{"sku", types.ScalarAttributeTypeS})
+	testProductListOrder(t, repositories.NewDynamoDBProductRepository(client, products, skus))
+}
@@ -1,42 +1,303 @@
 package repositories
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"store_product/models"
-	"sync"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
 )
 
-// ProductRepository handles product data operations
-type ProductRepository struct {
-	store sync.Map
+// mysqlErrDuplicateEntry is the MySQL error number for a unique key violation
+const mysqlErrDuplicateEntry = 1062
+
+// MySQLProductRepository handles product data operations for MySQL
+type MySQLProductRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLProductRepository creates a new MySQL product repository
+func NewMySQLProductRepository(db *sql.DB) *MySQLProductRepository {
+	return &MySQLProductRepository{db: db}
+}
+
+// Ensure MySQLProductRepository implements ProductRepositoryInterface
+var _ ProductRepositoryInterface = (*MySQLProductRepository)(nil)
+
+// productColumns lists the products columns in the order scanProduct reads them
+const productColumns = "product_id, sku, manufacturer, category_id, weight, some_other_id, price_amount, price_currency"
+
+// scanProduct reads a row of productColumns
+func scanProduct(row interface{ Scan(...interface{}) error }) (*models.Product, error) {
+	var p models.Product
+	var priceAmount sql.NullInt64
+	var priceCurrency sql.NullString
+	err := row.Scan(&p.ProductID, &p.SKU, &p.Manufacturer, &p.CategoryID, &p.Weight, &p.SomeOtherID, &priceAmount, &priceCurrency)
+	if err != nil {
+		return nil, err
+	}
+	p.Price = nullMoney(priceAmount, priceCurrency)
+	return &p, nil
 }
 
-// NewProductRepository creates a new product repository
-func NewProductRepository() *ProductRepository {
-	return &ProductRepository{}
+// moneyArgs returns the amount and currency column values of an optional price
+func moneyArgs(m *models.Money) (sql.NullInt64, sql.NullString) {
+	if m == nil {
+		return sql.NullInt64{}, sql.NullString{}
+	}
+	return sql.NullInt64{Int64: m.Amount, Valid: true}, sql.NullString{String: m.Currency, Valid: true}
 }
 
 // GetByID retrieves a product by ID
-func (r *ProductRepository) GetByID(id int) (*models.Product, bool) {
-	value, ok := r.store.Load(id)
-	if !ok {
-		return nil, false
+func (r *MySQLProductRepository) GetByID(ctx context.Context, id int) (*models.Product, error) {
+	p, err := scanProduct(r.db.QueryRowContext(ctx, `
+		SELECT `+productColumns+`
+		FROM products WHERE product_id = ?
+	`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch product: %w", err)
+	}
+	return p, nil
+}
+
+// GetByIDs retrieves several products in one query
+func (r *MySQLProductRepository) GetByIDs(ctx context.Context, ids []int) ([]models.Product, error) {
+	products := []models.Product{}
+	if len(ids) == 0 {
+		return products, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
 	}
-	product := value.(models.Product)
-	return &product, true
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+productColumns+`
+		FROM products WHERE product_id IN (?`+strings.Repeat(", ?", len(ids)-1)+`)
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch products: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating product rows: %w", err)
+	}
+	return products, nil
+}
+
+// GetBySKU retrieves a product by SKU
+func (r *MySQLProductRepository) GetBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	p, err := scanProduct(r.db.QueryRowContext(ctx, `
+		SELECT `+productColumns+`
+		FROM products WHERE sku = ?
+	`, sku))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch product by SKU: %w", err)
+	}
+	return p, nil
 }
 
-// Save stores a product
-func (r *ProductRepository) Save(product models.Product) {
-	r.store.Store(product.ProductID, product)
+// Create stores a new product
+func (r *MySQLProductRepository) Create(ctx context.Context, product models.Product) error {
+	priceAmount, priceCurrency := moneyArgs(product.Price)
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO products (`+productColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, product.ProductID, product.SKU, product.Manufacturer, product.CategoryID, product.Weight, product.SomeOtherID,
+		priceAmount, priceCurrency)
+	if err != nil {
+		if conflict := productConflict(err); conflict != nil {
+			return conflict
+		}
+		return fmt.Errorf("failed to create product: %w", err)
+	}
+	return nil
+}
+
+// Update replaces an existing product
+func (r *MySQLProductRepository) Update(ctx context.Context, product models.Product) error {
+	priceAmount, priceCurrency := moneyArgs(product.Price)
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE products
+		SET sku = ?, manufacturer = ?, category_id = ?, weight = ?, some_other_id = ?,
+			price_amount = ?, price_currency = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE product_id = ?
+	`, product.SKU, product.Manufacturer, product.CategoryID, product.Weight, product.SomeOtherID,
+		priceAmount, priceCurrency, product.ProductID)
+	if err != nil {
+		if conflict := productConflict(err); conflict != nil {
+			return conflict
+		}
+		return fmt.Errorf("failed to update product: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update product: %w", err)
+	}
+	if affected == 0 {
+		// MySQL also reports zero rows when nothing actually changed
+		exists, err := r.Exists(ctx, product.ProductID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrProductNotFound
+		}
+	}
+	return nil
 }
 
 // Delete removes a product
-func (r *ProductRepository) Delete(id int) {
-	r.store.Delete(id)
+func (r *MySQLProductRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM products WHERE product_id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete product: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete product: %w", err)
+	}
+	if affected == 0 {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+// productConflict maps a duplicate-key error to ErrDuplicateSKU or
+// ErrProductExists depending on the violated key, or returns nil
+func productConflict(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != mysqlErrDuplicateEntry {
+		return nil
+	}
+	if strings.Contains(mysqlErr.Message, "uq_products_sku") {
+		return ErrDuplicateSKU
+	}
+	return ErrProductExists
 }
 
 // Exists checks if a product exists
-func (r *ProductRepository) Exists(id int) bool {
-	_, ok := r.store.Load(id)
-	return ok
+func (r *MySQLProductRepository) Exists(ctx context.Context, id int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM products WHERE product_id = ?)",
+		id,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check product existence: %w", err)
+	}
+	return exists, nil
 }
+
+// List retrieves a filtered, sorted page of products using keyset pagination
+func (r *MySQLProductRepository) List(ctx context.Context, opts ProductListOptions) ([]models.Product, string, error) {
+	cursor, err := parseProductCursor(opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var conditions []string
+	var args []interface{}
+	f := opts.Filter
+	if f.Manufacturer != "" {
+		conditions = append(conditions, "manufacturer = ?")
+		args = append(args, f.Manufacturer)
+	}
+	if f.CategoryID != 0 {
+		conditions = append(conditions, "category_id = ?")
+		args = append(args, f.CategoryID)
+	}
+	if f.MinWeight != nil {
+		conditions = append(conditions, "weight >= ?")
+		args = append(args, *f.MinWeight)
+	}
+	if f.MaxWeight != nil {
+		conditions = append(conditions, "weight <= ?")
+		args = append(args, *f.MaxWeight)
+	}
+	if f.SKUPrefix != "" {
+		conditions = append(conditions, "sku LIKE ?")
+		args = append(args, likeEscaper.Replace(f.SKUPrefix)+"%")
+	}
+
+	// The sort field is one of the ProductSortField constants, so it is safe
+	// to use as a column name
+	column := string(opts.sortBy())
+	op, dir := ">", "ASC"
+	if opts.Descending {
+		op, dir = "<", "DESC"
+	}
+	if cursor != nil {
+		if column == "product_id" {
+			conditions = append(conditions, "product_id "+op+" ?")
+			args = append(args, cursor.ProductID)
+		} else {
+			value := cursor.sortValue()
+			conditions = append(conditions, fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND product_id %[2]s ?))", column, op))
+			args = append(args, value, value, cursor.ProductID)
+		}
+	}
+
+	query := "SELECT " + productColumns + " FROM products"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	if column == "product_id" {
+		query += " ORDER BY product_id " + dir
+	} else {
+		query += fmt.Sprintf(" ORDER BY %s %s, product_id %s", column, dir, dir)
+	}
+	// Fetch one extra row to learn whether another page exists
+	query += " LIMIT ?"
+	args = append(args, opts.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list products: %w", err)
+	}
+	defer rows.Close()
+
+	products := []models.Product{}
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("error iterating product rows: %w", err)
+	}
+
+	var next string
+	if len(products) > opts.Limit {
+		products = products[:opts.Limit]
+		next, err = newProductCursor(products[len(products)-1], opts)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+	return products, next, nil
+}
+
+// likeEscaper escapes the LIKE wildcards in a literal prefix
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
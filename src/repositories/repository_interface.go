@@ -1,12 +1,69 @@
 package repositories
 
-import "store_product/models"
+import (
+	"context"
+	"store_product/models"
+)
 
-// CartRepositoryInterface defines the contract for cart data operations
+// CartListOptions controls paging of a customer's cart listing
+type CartListOptions struct {
+	Limit        int    // maximum number of carts to return
+	Cursor       string // opaque continuation token from a previous page
+	IncludeItems bool   // load each cart's items as well
+}
+
+// CartRepositoryInterface defines the contract for cart data operations.
+// Every method takes the request context so that client disconnects and
+// request deadlines cancel in-flight database calls.
 type CartRepositoryInterface interface {
-	Create(customerID int) (interface{}, error)
-	GetByID(cartID interface{}) (*models.ShoppingCart, error)
-	Exists(cartID interface{}) (bool, error)
-	AddItem(cartID interface{}, productID, quantity int) error
-	GetByCustomerID(customerID int) ([]models.ShoppingCart, error)
+	Create(ctx context.Context, customerID int) (models.CartID, error)
+	GetByID(ctx context.Context, cartID models.CartID) (*models.ShoppingCart, error)
+	Exists(ctx context.Context, cartID models.CartID) (bool, error)
+	AddItem(ctx context.Context, cartID models.CartID, productID, quantity int, price *models.Money) error
+	UpdateItemQuantity(ctx context.Context, cartID models.CartID, productID, quantity int) error
+	RemoveItem(ctx context.Context, cartID models.CartID, productID int) error
+	ClearItems(ctx context.Context, cartID models.CartID) error
+	Delete(ctx context.Context, cartID models.CartID) error
+	GetByCustomerID(ctx context.Context, customerID int, opts CartListOptions) (carts []models.ShoppingCart, nextCursor string, err error)
+	Checkout(ctx context.Context, cartID models.CartID) (models.OrderID, error)
+}
+
+// ProductRepositoryInterface defines the contract for product data operations.
+// SKUs are unique: writes that would share a SKU between two products fail
+// with ErrDuplicateSKU.
+type ProductRepositoryInterface interface {
+	GetByID(ctx context.Context, id int) (*models.Product, error)
+	// GetByIDs retrieves several products at once, in no particular order.
+	// IDs of products that do not exist are left out.
+	GetByIDs(ctx context.Context, ids []int) ([]models.Product, error)
+	GetBySKU(ctx context.Context, sku string) (*models.Product, error)
+	Create(ctx context.Context, product models.Product) error
+	Update(ctx context.Context, product models.Product) error
+	Delete(ctx context.Context, id int) error
+	Exists(ctx context.Context, id int) (bool, error)
+	List(ctx context.Context, opts ProductListOptions) (products []models.Product, nextCursor string, err error)
+}
+
+// InventoryRepositoryInterface defines the contract for warehouse inventory operations.
+// Implementations must apply each operation atomically so that concurrent
+// requests can never drive reserved stock above on-hand stock or below zero.
+type InventoryRepositoryInterface interface {
+	Get(ctx context.Context, productID int) (*models.Inventory, error)
+	Restock(ctx context.Context, productID, quantity int) error
+	Reserve(ctx context.Context, productID, quantity int) error
+	Ship(ctx context.Context, productID, quantity int) error
+}
+
+// PaymentRepositoryInterface defines the contract for payment transaction storage
+type PaymentRepositoryInterface interface {
+	// Save records a payment. Saving an approved payment also marks its cart
+	// paid in the same write, which freezes the cart's items; a cart has at
+	// most one approved payment, so a second one fails with ErrCartPaid.
+	// Payments for checked-out or unknown carts fail with ErrCartCheckedOut
+	// or ErrCartNotFound.
+	Save(ctx context.Context, payment *models.Payment) error
+	GetByTransactionID(ctx context.Context, transactionID string) (*models.Payment, error)
+	// GetApprovedByCartID returns the approved payment for a cart, or nil if
+	// the cart has not been paid
+	GetApprovedByCartID(ctx context.Context, cartID models.CartID) (*models.Payment, error)
 }
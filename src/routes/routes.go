@@ -1,54 +1,187 @@
 package routes
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"log/slog"
 
+	"store_product/auth"
+	"store_product/config"
 	"store_product/handlers"
+	"store_product/metrics"
+	"store_product/middleware"
+	"store_product/payments"
 	"store_product/repositories"
+	"store_product/tracing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/gin-gonic/gin"
 )
 
 // SetupRoutes configures all application routes with MySQL
-func SetupRoutes(router *gin.Engine, db *sql.DB) {
-	// Initialize repositories
-	productRepo := repositories.NewProductRepository()
-	cartRepo := repositories.NewMySQLCartRepository(db)
+func SetupRoutes(router *gin.Engine, cfg *config.Config, logger *slog.Logger, healthHandler *handlers.HealthHandler, db *sql.DB) {
+	// Initialize repositories, tracing and recording their operations under the backend label
+	logger = logger.With("backend", "mysql")
+	inst := repositories.Instrumentation{
+		Backend:  "mysql",
+		Observer: metrics.RepositoryObserver("mysql"),
+		Logger:   logger,
+		Tracer:   tracing.Tracer(),
+	}
+	productRepo := repositories.NewInstrumentedProductRepository(repositories.NewMySQLProductRepository(db), inst)
+	cartRepo := repositories.NewInstrumentedCartRepository(repositories.NewMySQLCartRepository(db), inst)
+	inventoryRepo := repositories.NewInstrumentedInventoryRepository(repositories.NewMySQLInventoryRepository(db), inst)
+	paymentRepo := repositories.NewInstrumentedPaymentRepository(repositories.NewMySQLPaymentRepository(db), inst)
+
+	// Export the connection pool statistics alongside the operation metrics
+	metrics.RegisterDBStats(db)
+
+	// Readiness depends on the connection pool reaching MySQL
+	healthHandler.AddDependency(handlers.Dependency{Name: "mysql", Critical: true, Check: db.PingContext})
 
 	// Initialize handlers
-	healthHandler := handlers.NewHealthHandler()
-	productHandler := handlers.NewProductHandler(productRepo)
-	cartHandler := handlers.NewCartHandler(cartRepo)
+	productHandler := handlers.NewProductHandler(productRepo, logger)
+	cartHandler := handlers.NewCartHandler(cartRepo, productRepo, logger)
+	warehouseHandler := handlers.NewWarehouseHandler(inventoryRepo, productRepo, logger)
+	paymentHandler := handlers.NewPaymentHandler(newPaymentProcessor(cfg.Payments), paymentRepo, cartRepo, productRepo, logger)
 
-	setupCommonRoutes(router, healthHandler, productHandler, cartHandler)
+	setupCommonRoutes(router, cfg, logger, healthHandler, productHandler, cartHandler, warehouseHandler, paymentHandler)
 }
 
 // SetupRoutesWithDynamoDB configures all application routes with DynamoDB
-func SetupRoutesWithDynamoDB(router *gin.Engine, client *dynamodb.Client, tableName string) {
-	// Initialize repositories
-	productRepo := repositories.NewProductRepository()
-	cartRepo := repositories.NewDynamoDBCartRepository(client, tableName)
+func SetupRoutesWithDynamoDB(router *gin.Engine, cfg *config.Config, logger *slog.Logger, healthHandler *handlers.HealthHandler, client *dynamodb.Client) {
+	tables := cfg.DynamoDB.Tables
+
+	// Initialize repositories, tracing and recording their operations under the backend label
+	logger = logger.With("backend", "dynamodb")
+	inst := repositories.Instrumentation{
+		Backend:  "dynamodb",
+		Observer: metrics.RepositoryObserver("dynamodb"),
+		Logger:   logger,
+		Tracer:   tracing.Tracer(),
+	}
+	productRepo := repositories.NewInstrumentedProductRepository(repositories.NewDynamoDBProductRepository(client, tables.Products, tables.ProductSKUs), inst)
+	cartRepo := repositories.NewInstrumentedCartRepository(repositories.NewDynamoDBCartRepository(client, tables.Carts, tables.Orders, cfg.CartTTL), inst)
+	inventoryRepo := repositories.NewInstrumentedInventoryRepository(repositories.NewDynamoDBInventoryRepository(client, tables.Inventory), inst)
+	paymentRepo := repositories.NewInstrumentedPaymentRepository(repositories.NewDynamoDBPaymentRepository(client, tables.Payments, tables.Carts), inst)
+
+	// Readiness depends on reaching DynamoDB and the carts table being usable
+	healthHandler.AddDependency(handlers.Dependency{
+		Name:     "dynamodb",
+		Critical: true,
+		Check:    dynamoDBTableCheck(client, tables.Carts),
+	})
+
+	// Initialize handlers
+	productHandler := handlers.NewProductHandler(productRepo, logger)
+	cartHandler := handlers.NewCartHandler(cartRepo, productRepo, logger)
+	warehouseHandler := handlers.NewWarehouseHandler(inventoryRepo, productRepo, logger)
+	paymentHandler := handlers.NewPaymentHandler(newPaymentProcessor(cfg.Payments), paymentRepo, cartRepo, productRepo, logger)
+
+	setupCommonRoutes(router, cfg, logger, healthHandler, productHandler, cartHandler, warehouseHandler, paymentHandler)
+}
+
+// SetupRoutesWithMemory configures all application routes with in-process
+// storage, for local development without a database. Data is lost on restart.
+func SetupRoutesWithMemory(router *gin.Engine, cfg *config.Config, logger *slog.Logger, healthHandler *handlers.HealthHandler) {
+	// Initialize repositories, tracing and recording their operations under the backend label
+	logger = logger.With("backend", "memory")
+	inst := repositories.Instrumentation{
+		Backend:  "memory",
+		Observer: metrics.RepositoryObserver("memory"),
+		Logger:   logger,
+		Tracer:   tracing.Tracer(),
+	}
+	productRepo := repositories.NewInstrumentedProductRepository(repositories.NewInMemoryProductRepository(), inst)
+	carts := repositories.NewInMemoryCartRepository(cfg.CartTTL)
+	cartRepo := repositories.NewInstrumentedCartRepository(carts, inst)
+	inventoryRepo := repositories.NewInstrumentedInventoryRepository(repositories.NewInMemoryInventoryRepository(), inst)
+	paymentRepo := repositories.NewInstrumentedPaymentRepository(repositories.NewInMemoryPaymentRepository(carts), inst)
 
 	// Initialize handlers
-	healthHandler := handlers.NewHealthHandler()
-	productHandler := handlers.NewProductHandler(productRepo)
-	cartHandler := handlers.NewCartHandler(cartRepo)
+	productHandler := handlers.NewProductHandler(productRepo, logger)
+	cartHandler := handlers.NewCartHandler(cartRepo, productRepo, logger)
+	warehouseHandler := handlers.NewWarehouseHandler(inventoryRepo, productRepo, logger)
+	paymentHandler := handlers.NewPaymentHandler(newPaymentProcessor(cfg.Payments), paymentRepo, cartRepo, productRepo, logger)
 
-	setupCommonRoutes(router, healthHandler, productHandler, cartHandler)
+	setupCommonRoutes(router, cfg, logger, healthHandler, productHandler, cartHandler, warehouseHandler, paymentHandler)
 }
 
 // setupCommonRoutes sets up routes common to all database types
-func setupCommonRoutes(router *gin.Engine, healthHandler *handlers.HealthHandler, productHandler *handlers.ProductHandler, cartHandler *handlers.CartHandler) {
-	// Health check
+func setupCommonRoutes(router *gin.Engine, cfg *config.Config, logger *slog.Logger, healthHandler *handlers.HealthHandler, productHandler *handlers.ProductHandler, cartHandler *handlers.CartHandler, warehouseHandler *handlers.WarehouseHandler, paymentHandler *handlers.PaymentHandler) {
+	// Count and time every request, including rejected and unmatched ones
+	router.Use(middleware.Metrics())
+
+	// Health checks
 	router.GET("/health", healthHandler.Check)
+	router.GET("/health/live", healthHandler.Live)
+	router.GET("/health/ready", healthHandler.Ready)
+
+	// Prometheus scrape endpoint; like the health checks it needs no credentials
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// Every other route requires an API key or bearer token and is bounded by
+	// the configured deadline. The middleware is mounted on a group rather than
+	// the router so unknown paths still get a 404 instead of a 401.
+	api := router.Group("/", middleware.Authenticate(auth.NewAuthenticator(cfg.Auth), logger), middleware.RequestTimeout(cfg.Server.RequestTimeout))
 
 	// Product routes
-	router.GET("/products/:productId", productHandler.GetByID)
-	router.POST("/products", productHandler.Create)
+	api.GET("/products", productHandler.List)
+	api.GET("/products/:productId", productHandler.GetByID)
+	api.GET("/products/sku/:sku", productHandler.GetBySKU)
+	api.POST("/products", productHandler.Create)
+	api.PUT("/products/:productId", productHandler.Replace)
+	api.PATCH("/products/:productId", productHandler.Patch)
+	api.DELETE("/products/:productId", productHandler.Delete)
+	api.POST("/products/:productId/details", productHandler.AddDetails)
 
 	// Shopping cart routes
-	router.POST("/shopping-carts", cartHandler.Create)
-	router.GET("/shopping-carts/:id", cartHandler.GetByID)
-	router.POST("/shopping-carts/:id/items", cartHandler.AddItem)
+	api.POST("/shopping-carts", cartHandler.Create)
+	api.GET("/shopping-carts/:id", cartHandler.GetByID)
+	api.DELETE("/shopping-carts/:id", cartHandler.Delete)
+	api.POST("/shopping-carts/:id/items", cartHandler.AddItem)
+	api.DELETE("/shopping-carts/:id/items", cartHandler.ClearItems)
+	api.PUT("/shopping-carts/:id/items/:productId", cartHandler.UpdateItem)
+	api.PATCH("/shopping-carts/:id/items/:productId", cartHandler.UpdateItem)
+	api.DELETE("/shopping-carts/:id/items/:productId", cartHandler.RemoveItem)
+	api.POST("/shopping-carts/:id/checkout", cartHandler.Checkout)
+	api.GET("/customers/:customerId/shopping-carts", cartHandler.ListByCustomer)
+
+	// Warehouse routes
+	api.GET("/warehouse/inventory/:productId", warehouseHandler.GetInventory)
+	api.POST("/warehouse/restock", warehouseHandler.Restock)
+	api.POST("/warehouse/reserve", warehouseHandler.Reserve)
+	api.POST("/warehouse/ship", warehouseHandler.Ship)
+
+	// Payment routes
+	api.POST("/payments/checkout", paymentHandler.Checkout)
+}
+
+// dynamoDBTableCheck returns a readiness probe that describes the table and
+// fails unless it can serve reads and writes
+func dynamoDBTableCheck(client *dynamodb.Client, table string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+		if err != nil {
+			return fmt.Errorf("failed to describe table %s: %w", table, err)
+		}
+		switch status := out.Table.TableStatus; status {
+		case types.TableStatusActive, types.TableStatusUpdating:
+			return nil
+		default:
+			return fmt.Errorf("table %s is %s", table, status)
+		}
+	}
+}
+
+// newPaymentProcessor builds the local card processor from the configured decline rules
+func newPaymentProcessor(cfg config.PaymentsConfig) payments.PaymentProcessor {
+	var rules []payments.DeclineRule
+	for _, prefix := range cfg.DeclinePrefixes {
+		rules = append(rules, payments.DeclinePrefix(prefix))
+	}
+	return payments.NewFakeProcessor(rules...)
 }
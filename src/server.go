@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"store_product/config"
+	"store_product/handlers"
+)
+
+// serve runs the HTTP server until SIGINT or SIGTERM, then drains it: the
+// health check starts failing, the server keeps serving for the configured
+// delay so the load balancer can stop routing to it, and then in-flight
+// requests get the shutdown timeout to finish. A second signal exits at once.
+func serve(cfg config.ServerConfig, handler http.Handler, health *handlers.HealthHandler, logger *slog.Logger) error {
+	server := &http.Server{
+		Addr:              cfg.Addr(),
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("server starting", "addr", server.Addr)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	// Restore default signal handling so a second signal terminates at once
+	stop()
+
+	delay, timeout := cfg.ShutdownDelay, cfg.ShutdownTimeout
+	logger.Info("shutdown requested; draining before closing connections", "delay", delay.String())
+	health.SetDraining()
+	time.Sleep(delay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("in-flight requests did not finish in time", "timeout", timeout.String(), "error", err.Error())
+		server.Close()
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceDynamoDB is a DynamoDB client option that wraps every operation made
+// within a traced request in a client span named after the operation and
+// recording the tables it touches
+func TraceDynamoDB(o *dynamodb.Options) {
+	o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("OTelTracing",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				if !InSpan(ctx) {
+					return next.HandleInitialize(ctx, in)
+				}
+				operation := awsmiddleware.GetOperationName(ctx)
+				ctx, span := Tracer().Start(ctx, "DynamoDB."+operation,
+					trace.WithSpanKind(trace.SpanKindClient),
+					trace.WithAttributes(
+						semconv.RPCSystemKey.String("aws-api"),
+						semconv.RPCService("DynamoDB"),
+						semconv.RPCMethod(operation),
+						semconv.DBSystemDynamoDB,
+						semconv.AWSDynamoDBTableNames(tableNames(in.Parameters)...),
+					),
+				)
+				defer span.End()
+
+				out, metadata, err := next.HandleInitialize(ctx, in)
+				if requestID, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+					span.SetAttributes(attribute.String("aws.request_id", requestID))
+				}
+				if err != nil {
+					span.RecordError(err)
+					span.SetStatus(codes.Error, err.Error())
+				}
+				return out, metadata, err
+			}), middleware.After)
+	})
+}
+
+// tableNames returns the tables an operation input refers to. Single-table
+// inputs have a TableName field; transactions name a table per item.
+func tableNames(params interface{}) []string {
+	if in, ok := params.(*dynamodb.TransactWriteItemsInput); ok {
+		seen := make(map[string]bool)
+		var names []string
+		for _, item := range in.TransactItems {
+			var table *string
+			switch {
+			case item.Put != nil:
+				table = item.Put.TableName
+			case item.Update != nil:
+				table = item.Update.TableName
+			case item.Delete != nil:
+				table = item.Delete.TableName
+			case item.ConditionCheck != nil:
+				table = item.ConditionCheck.TableName
+			}
+			if name := aws.ToString(table); name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+
+	v := reflect.ValueOf(params)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	field := v.Elem().FieldByName("TableName")
+	if !field.IsValid() {
+		return nil
+	}
+	if name, ok := field.Interface().(*string); ok && name != nil {
+		return []string{*name}
+	}
+	return nil
+}
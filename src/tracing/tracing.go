@@ -0,0 +1,83 @@
+// Package tracing sets up OpenTelemetry tracing. Requests are traced from the
+// gin middleware through the repositories down to each SQL statement or
+// DynamoDB operation, and W3C trace context is taken from incoming requests
+// so traces continue those of the caller.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans created by this service's own code
+const instrumentationName = "store_product"
+
+// Span exporters accepted by Init
+const (
+	ExporterNone   = "none"   // spans are not recorded
+	ExporterStdout = "stdout" // spans are written to stdout as JSON, for local verification
+	ExporterOTLP   = "otlp"   // spans are sent over OTLP/HTTP to OTEL_EXPORTER_OTLP_ENDPOINT
+)
+
+// Init installs the global tracer provider for the given exporter and the W3C
+// trace-context propagator. The returned function flushes buffered spans and
+// stops the provider; call it once the server has drained.
+func Init(ctx context.Context, exporter, serviceName string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New()
+	case ExporterOTLP:
+		spanExporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", exporter, err)
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the defaults
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer for spans created by this service's own code
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// InSpan reports whether ctx carries a span. Database calls are only traced
+// within a request, so readiness checks do not start a trace of their own.
+func InSpan(ctx context.Context) bool {
+	return trace.SpanContextFromContext(ctx).IsValid()
+}
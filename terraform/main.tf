@@ -27,11 +27,22 @@ module "alb" {
   cidr_blocks    = var.cidr_blocks
 }
 
+# Credentials accepted by the API's authentication middleware
+module "secrets" {
+  source       = "./modules/secrets"
+  service_name = var.service_name
+  secrets = {
+    API_KEYS         = var.api_keys
+    JWT_SIGNING_KEYS = var.jwt_signing_keys
+  }
+}
+
 # IAM roles module
 module "iam" {
   source        = "./modules/iam"
   service_name  = var.service_name
   database_type = var.database_type
+  secret_arns   = module.secrets.parameter_arns
 }
 
 # Conditionally create MySQL RDS instance
@@ -71,6 +82,7 @@ module "ecs" {
   memory                    = var.memory
   target_group_arn          = module.alb.target_group_arn
   enable_auto_scaling       = var.enable_auto_scaling
+  secrets                   = module.secrets.container_secrets
   environment_variables = var.database_type == "mysql" ? [
     {
       name  = "DATABASE_TYPE"
@@ -105,6 +117,26 @@ module "ecs" {
       name  = "DYNAMODB_TABLE_NAME"
       value = module.dynamodb[0].table_name
     },
+    {
+      name  = "DYNAMODB_ORDERS_TABLE_NAME"
+      value = module.dynamodb[0].orders_table_name
+    },
+    {
+      name  = "DYNAMODB_INVENTORY_TABLE_NAME"
+      value = module.dynamodb[0].inventory_table_name
+    },
+    {
+      name  = "DYNAMODB_PAYMENTS_TABLE_NAME"
+      value = module.dynamodb[0].payments_table_name
+    },
+    {
+      name  = "DYNAMODB_PRODUCTS_TABLE_NAME"
+      value = module.dynamodb[0].products_table_name
+    },
+    {
+      name  = "DYNAMODB_PRODUCT_SKUS_TABLE_NAME"
+      value = module.dynamodb[0].product_skus_table_name
+    },
     {
       name  = "AWS_REGION"
       value = var.aws_region
@@ -47,16 +47,20 @@ resource "aws_lb_target_group" "this" {
   vpc_id      = var.vpc_id
   target_type = "ip"
 
+  # A draining task fails /health/ready and is taken out of rotation within
+  # interval * unhealthy_threshold (10s) plus one interval. The service's
+  # SHUTDOWN_DELAY (15s) must cover that, and with SHUTDOWN_TIMEOUT stay below
+  # the 30s ECS waits before killing the task.
   health_check {
     enabled             = true
     healthy_threshold   = 2
-    interval            = 30
+    interval            = 5
     matcher             = "200"
-    path                = "/health"
+    path                = "/health/ready"
     port                = "traffic-port"
     protocol            = "HTTP"
-    timeout             = 5
-    unhealthy_threshold = 3
+    timeout             = 3
+    unhealthy_threshold = 2
   }
 
   tags = {
@@ -14,10 +14,17 @@ resource "aws_dynamodb_table" "carts" {
     type = "N"
   }
 
-  # Global Secondary Index for querying by customer_id
+  attribute {
+    name = "created_at"
+    type = "S"
+  }
+
+  # Global Secondary Index listing a customer's carts newest first. created_at
+  # is written as RFC 3339 with a fixed-width fraction so it sorts as a string.
   global_secondary_index {
     name            = "customer-index"
     hash_key        = "customer_id"
+    range_key       = "created_at"
     projection_type = "ALL"
   }
 
@@ -38,3 +45,194 @@ resource "aws_dynamodb_table" "carts" {
     Service     = var.service_name
   }
 }
+
+# DynamoDB table for orders created at checkout
+resource "aws_dynamodb_table" "orders" {
+  name         = "${var.service_name}-orders-${var.environment}"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "order_id"
+
+  attribute {
+    name = "order_id"
+    type = "S"
+  }
+
+  # Point-in-time recovery
+  point_in_time_recovery {
+    enabled = var.enable_point_in_time_recovery
+  }
+
+  tags = {
+    Name        = "${var.service_name}-orders-${var.environment}"
+    Environment = var.environment
+    Service     = var.service_name
+  }
+}
+
+# DynamoDB table for warehouse inventory
+resource "aws_dynamodb_table" "inventory" {
+  name         = "${var.service_name}-inventory-${var.environment}"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "product_id"
+
+  attribute {
+    name = "product_id"
+    type = "N"
+  }
+
+  # Point-in-time recovery
+  point_in_time_recovery {
+    enabled = var.enable_point_in_time_recovery
+  }
+
+  tags = {
+    Name        = "${var.service_name}-inventory-${var.environment}"
+    Environment = var.environment
+    Service     = var.service_name
+  }
+}
+
+# DynamoDB table for payment transactions
+resource "aws_dynamodb_table" "payments" {
+  name         = "${var.service_name}-payments-${var.environment}"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "transaction_id"
+
+  attribute {
+    name = "transaction_id"
+    type = "S"
+  }
+
+  attribute {
+    name = "cart_id"
+    type = "S"
+  }
+
+  # Global Secondary Index for finding the payments of a cart
+  global_secondary_index {
+    name            = "cart-index"
+    hash_key        = "cart_id"
+    projection_type = "ALL"
+  }
+
+  # Point-in-time recovery
+  point_in_time_recovery {
+    enabled = var.enable_point_in_time_recovery
+  }
+
+  tags = {
+    Name        = "${var.service_name}-payments-${var.environment}"
+    Environment = var.environment
+    Service     = var.service_name
+  }
+}
+
+# DynamoDB table for the product catalog
+resource "aws_dynamodb_table" "products" {
+  name         = "${var.service_name}-products-${var.environment}"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "product_id"
+
+  attribute {
+    name = "product_id"
+    type = "N"
+  }
+
+  attribute {
+    name = "catalog"
+    type = "S"
+  }
+
+  attribute {
+    name = "sku"
+    type = "S"
+  }
+
+  attribute {
+    name = "catalog_manufacturer"
+    type = "S"
+  }
+
+  attribute {
+    name = "catalog_category_id"
+    type = "S"
+  }
+
+  attribute {
+    name = "catalog_weight"
+    type = "S"
+  }
+
+  # Global Secondary Indexes listing the catalog in each sort order. Products
+  # are spread over a few shards, catalog = "product#<product_id mod 4>", which
+  # the service queries and merges; the sort keys of fields that are not
+  # unique end in the product ID.
+  global_secondary_index {
+    name            = "catalog-product_id-index"
+    hash_key        = "catalog"
+    range_key       = "product_id"
+    projection_type = "ALL"
+  }
+
+  global_secondary_index {
+    name            = "catalog-sku-index"
+    hash_key        = "catalog"
+    range_key       = "sku"
+    projection_type = "ALL"
+  }
+
+  global_secondary_index {
+    name            = "catalog-manufacturer-index"
+    hash_key        = "catalog"
+    range_key       = "catalog_manufacturer"
+    projection_type = "ALL"
+  }
+
+  global_secondary_index {
+    name            = "catalog-category_id-index"
+    hash_key        = "catalog"
+    range_key       = "catalog_category_id"
+    projection_type = "ALL"
+  }
+
+  global_secondary_index {
+    name            = "catalog-weight-index"
+    hash_key        = "catalog"
+    range_key       = "catalog_weight"
+    projection_type = "ALL"
+  }
+
+  # Point-in-time recovery
+  point_in_time_recovery {
+    enabled = var.enable_point_in_time_recovery
+  }
+
+  tags = {
+    Name        = "${var.service_name}-products-${var.environment}"
+    Environment = var.environment
+    Service     = var.service_name
+  }
+}
+
+# DynamoDB table reserving each product SKU, so SKUs stay unique across products
+resource "aws_dynamodb_table" "product_skus" {
+  name         = "${var.service_name}-product-skus-${var.environment}"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "sku"
+
+  attribute {
+    name = "sku"
+    type = "S"
+  }
+
+  # Point-in-time recovery
+  point_in_time_recovery {
+    enabled = var.enable_point_in_time_recovery
+  }
+
+  tags = {
+    Name        = "${var.service_name}-product-skus-${var.environment}"
+    Environment = var.environment
+    Service     = var.service_name
+  }
+}
@@ -7,3 +7,53 @@ output "table_arn" {
   description = "ARN of the DynamoDB table"
   value       = aws_dynamodb_table.carts.arn
 }
+
+output "orders_table_name" {
+  description = "Name of the DynamoDB orders table"
+  value       = aws_dynamodb_table.orders.name
+}
+
+output "orders_table_arn" {
+  description = "ARN of the DynamoDB orders table"
+  value       = aws_dynamodb_table.orders.arn
+}
+
+output "inventory_table_name" {
+  description = "Name of the DynamoDB inventory table"
+  value       = aws_dynamodb_table.inventory.name
+}
+
+output "inventory_table_arn" {
+  description = "ARN of the DynamoDB inventory table"
+  value       = aws_dynamodb_table.inventory.arn
+}
+
+output "payments_table_name" {
+  description = "Name of the DynamoDB payments table"
+  value       = aws_dynamodb_table.payments.name
+}
+
+output "payments_table_arn" {
+  description = "ARN of the DynamoDB payments table"
+  value       = aws_dynamodb_table.payments.arn
+}
+
+output "products_table_name" {
+  description = "Name of the DynamoDB products table"
+  value       = aws_dynamodb_table.products.name
+}
+
+output "products_table_arn" {
+  description = "ARN of the DynamoDB products table"
+  value       = aws_dynamodb_table.products.arn
+}
+
+output "product_skus_table_name" {
+  description = "Name of the DynamoDB product SKUs table"
+  value       = aws_dynamodb_table.product_skus.name
+}
+
+output "product_skus_table_arn" {
+  description = "ARN of the DynamoDB product SKUs table"
+  value       = aws_dynamodb_table.product_skus.arn
+}
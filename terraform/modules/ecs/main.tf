@@ -24,6 +24,7 @@ resource "aws_ecs_task_definition" "this" {
     }]
 
     environment = var.environment_variables
+    secrets     = var.secrets
 
     logConfiguration = {
       logDriver = "awslogs"
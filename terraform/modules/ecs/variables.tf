@@ -117,3 +117,12 @@ variable "environment_variables" {
   default     = []
   description = "Environment variables for the container"
 }
+
+variable "secrets" {
+  type = list(object({
+    name      = string
+    valueFrom = string
+  }))
+  default     = []
+  description = "Environment variables the container reads from SSM parameters or Secrets Manager, by ARN"
+}
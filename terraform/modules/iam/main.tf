@@ -29,6 +29,25 @@ resource "aws_iam_role_policy_attachment" "ecs_task_execution_role_policy" {
   policy_arn = "arn:aws:iam::aws:policy/service-role/AmazonECSTaskExecutionRolePolicy"
 }
 
+# Let ECS read the container's secrets from SSM Parameter Store. They use the
+# default aws/ssm key, so no kms:Decrypt grant is needed.
+resource "aws_iam_role_policy" "ecs_task_execution_secrets" {
+  count = length(var.secret_arns) > 0 ? 1 : 0
+  name  = "${var.service_name}-ecs-task-execution-secrets"
+  role  = aws_iam_role.ecs_task_execution_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Action   = "ssm:GetParameters"
+        Effect   = "Allow"
+        Resource = var.secret_arns
+      }
+    ]
+  })
+}
+
 # ECS Task Role - Used by the application container
 resource "aws_iam_role" "ecs_task_role" {
   name = "${var.service_name}-ecs-task-role"
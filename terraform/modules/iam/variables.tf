@@ -7,3 +7,9 @@ variable "database_type" {
   description = "Type of database (mysql or dynamodb)"
   type        = string
 }
+
+variable "secret_arns" {
+  description = "SSM parameters the task execution role reads to inject container secrets"
+  type        = list(string)
+  default     = []
+}
@@ -0,0 +1,12 @@
+# Credentials kept in SSM Parameter Store, encrypted with the account's
+# default aws/ssm key. ECS reads them at task start and passes them to the
+# container, so they never appear in the task definition.
+resource "aws_ssm_parameter" "this" {
+  # SSM rejects empty values, so an unset credential is not injected at all.
+  # Only the names of the set credentials are unmarked.
+  for_each = nonsensitive(toset([for name, value in var.secrets : name if value != ""]))
+
+  name  = "/${var.service_name}/${each.key}"
+  type  = "SecureString"
+  value = var.secrets[each.key]
+}
@@ -0,0 +1,9 @@
+output "container_secrets" {
+  description = "Entries for the container definition's secrets block"
+  value       = [for name, param in aws_ssm_parameter.this : { name = name, valueFrom = param.arn }]
+}
+
+output "parameter_arns" {
+  description = "ARNs of the parameters, for the task execution role"
+  value       = [for param in aws_ssm_parameter.this : param.arn]
+}
@@ -0,0 +1,10 @@
+variable "service_name" {
+  description = "Used as the parameter path prefix"
+  type        = string
+}
+
+variable "secrets" {
+  description = "Secret values by the environment variable they are injected as"
+  type        = map(string)
+  sensitive   = true
+}
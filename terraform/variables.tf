@@ -72,4 +72,18 @@ variable "database_type" {
     condition     = contains(["mysql", "dynamodb"], var.database_type)
     error_message = "database_type must be either 'mysql' or 'dynamodb'"
   }
-}
\ No newline at end of file
+}
+# Credentials accepted by the API's authentication middleware
+variable "api_keys" {
+  type        = string
+  description = "Comma separated name:key pairs accepted in the X-API-Key header"
+  default     = ""
+  sensitive   = true
+}
+
+variable "jwt_signing_keys" {
+  type        = string
+  description = "Comma separated HMAC secrets used to verify bearer tokens"
+  default     = ""
+  sensitive   = true
+}
@@ -1,8 +1,10 @@
 # PowerShell script to test all API endpoints locally
 # Make sure your Go application is running locally on port 8080
+# Pass -ApiKey (or set API_KEY) with a key from the server's API_KEYS setting
 
 param(
     [string]$BaseUrl = "http://localhost:8080",
+    [string]$ApiKey = $env:API_KEY,
     [switch]$Verbose
 )
 
@@ -33,6 +35,9 @@ function Test-Endpoint {
         $headers = @{
             "Content-Type" = "application/json"
         }
+        if ($ApiKey) {
+            $headers["X-API-Key"] = $ApiKey
+        }
         
         $params = @{
             Uri = $Url
@@ -116,6 +121,7 @@ function Start-APITests {
         category_id = 1
         weight = 100
         some_other_id = 123
+        price = @{ amount = 1999; currency = "USD" }
     } | ConvertTo-Json
     
     $createdProduct = Test-Endpoint -Method "POST" -Url "$BaseUrl/products" -Body $productBody -Description "Create Product"
@@ -203,6 +209,7 @@ function Start-LoadTests {
             category_id = $i
             weight = $i * 10
             some_other_id = $i * 100
+            price = @{ amount = $i * 100; currency = "USD" }
         } | ConvertTo-Json
         
         Test-Endpoint -Method "POST" -Url "$BaseUrl/products" -Body $productBody -Description "Load Test - Create Product $i"